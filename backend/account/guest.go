@@ -0,0 +1,175 @@
+package account
+
+import (
+	"context"
+	"irl-mafia-game/auth"
+	"irl-mafia-game/game"
+	"irl-mafia-game/user"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type GuestRequest struct {
+	Invite string `json:"invite" binding:"required"`
+	Name   string `json:"name" binding:"required,max=40"` // shown to the other players
+}
+
+type GuestResponse struct {
+	user.LoginResponse
+	GameID string `json:"gameId"`
+}
+
+// GuestHandler godoc
+// @Summary Join a game as a guest
+// @Description Join the game an invite code belongs to without signing up. Guests can play and chat in their games but can't create games, add friends or change account settings until they upgrade. Inactive guests are removed.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param guest body GuestRequest true "Invite code and name"
+// @Success 200 {object} GuestResponse
+// @Router /guests [post]
+func GuestHandler(s Stores, engine *game.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		var req GuestRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if strings.TrimSpace(req.Name) == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+			return
+		}
+
+		g, err := s.Games.FindByInvite(ctx, req.Invite)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "invite not found"})
+			return
+		}
+		if g.Started() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": game.ErrGameStarted.Error()})
+			return
+		}
+
+		guest, err := user.NewGuest(req.Name)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if err := s.Users.AddUser(ctx, guest); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if guest, err = s.Users.FindUserWithUsername(ctx, guest.Username); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := joinAsGuest(ctx, s, engine, g, guest.ID); err != nil {
+			if err := s.Users.DeleteUser(ctx, guest.ID); err != nil {
+				log.Println("Failed to remove guest:", err)
+			}
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		pair, err := s.Tokens.Issue(ctx, guest.ID, auth.ClientFrom(c))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
+			return
+		}
+		c.JSON(http.StatusOK, GuestResponse{
+			LoginResponse: user.LoginResponse{
+				ID:           guest.ID.Hex(),
+				Username:     guest.Username,
+				Token:        pair.AccessToken,
+				RefreshToken: pair.RefreshToken,
+				ExpiresIn:    pair.ExpiresIn,
+			},
+			GameID: g.ID.Hex(),
+		})
+	}
+}
+
+func joinAsGuest(ctx context.Context, s Stores, engine *game.Engine, g game.Game, guestID primitive.ObjectID) error {
+	var joinErr error
+	err := game.Save(ctx, s.Games, &g, func(g *game.Game) bool {
+		joinErr = engine.Join(g, guestID)
+		return joinErr == nil
+	})
+	if joinErr != nil {
+		return joinErr
+	}
+	if err != nil {
+		return err
+	}
+	return s.Users.AddGameToUser(ctx, guestID, g.ID)
+}
+
+// GuestCleaner removes guests that haven't played for user.GuestTTL,
+// the same way users delete their own accounts.
+type GuestCleaner struct {
+	stores Stores
+	now    func() time.Time
+}
+
+func NewGuestCleaner(s Stores) *GuestCleaner {
+	return &GuestCleaner{stores: s, now: time.Now}
+}
+
+// Run sweeps every interval until ctx is done.
+func (gc *GuestCleaner) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := gc.Sweep(ctx); err != nil {
+			log.Println("Failed to clean up guests:", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep deletes inactive guests. A guest not seen lately who has still
+// acted in one of their games is marked as seen then instead.
+func (gc *GuestCleaner) Sweep(ctx context.Context) error {
+	cutoff := gc.now().Add(-user.GuestTTL)
+	guests, err := gc.stores.Users.FindGuests(ctx, cutoff)
+	if err != nil {
+		return err
+	}
+
+	for _, u := range guests {
+		games, err := gc.stores.Games.FindByPlayers(ctx, []primitive.ObjectID{u.ID})
+		if err != nil {
+			return err
+		}
+		seen := u.LastSeen
+		for _, g := range games {
+			for _, p := range g.PlayerStates {
+				if p.User == u.ID && p.LastAction.After(seen) {
+					seen = p.LastAction
+				}
+			}
+		}
+
+		if seen.After(cutoff) {
+			if err := gc.stores.Users.TouchGuest(ctx, u.ID, seen); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := gc.stores.delete(ctx, u); err != nil {
+			return err
+		}
+	}
+	return nil
+}
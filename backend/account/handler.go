@@ -0,0 +1,279 @@
+package account
+
+import (
+	"context"
+	"irl-mafia-game/auth"
+	"irl-mafia-game/chat"
+	"irl-mafia-game/chatbot"
+	"irl-mafia-game/friends"
+	"irl-mafia-game/game"
+	"irl-mafia-game/notifications"
+	"irl-mafia-game/user"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Stores is every place personal data lives.
+type Stores struct {
+	Users     user.UserRepository
+	Games     game.GameRepository
+	Messages  chat.MessageRepository
+	Devices   notifications.DeviceRepository
+	Settings  notifications.SettingsRepository
+	ChatLinks chatbot.LinkRepository
+	Friends   friends.FriendRepository
+	Tokens    *auth.Tokens
+	Logins    *auth.LoginGuard
+	Resets    user.ResetRepository
+	Avatars   user.AvatarStore
+}
+
+type DeleteAccountRequest struct {
+	Confirm string `json:"confirm" binding:"required"` // the account's username
+}
+
+// ExportHandler godoc
+// @Summary Export your data
+// @Description Download a JSON archive of everything stored about the current user
+// @Tags users
+// @Produce json
+// @Success 200 {object} Export
+// @Router /users/me/export [get]
+// @Security BearerAuth
+func ExportHandler(s Stores) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userObjID, ok := auth.CurrentUser(c)
+		if !ok {
+			return
+		}
+
+		export, err := s.export(c.Request.Context(), userObjID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Header("Content-Disposition", `attachment; filename="irl-mafia-game-export.json"`)
+		c.JSON(http.StatusOK, export)
+	}
+}
+
+func (s Stores) export(ctx context.Context, userID primitive.ObjectID) (Export, error) {
+	u, err := s.Users.FindUserWithID(ctx, userID)
+	if err != nil {
+		return Export{}, err
+	}
+	export := Export{
+		ExportedAt: time.Now(),
+		User: ExportUser{
+			ID:         u.ID.Hex(),
+			Username:   u.Username,
+			Email:      u.Email,
+			Profile:    u.Profile.Response(),
+			Identities: u.Identities,
+		},
+		Games:     []ExportGame{},
+		Friends:   []ExportFriend{},
+		Devices:   []ExportDevice{},
+		ChatLinks: []chatbot.Link{},
+	}
+	if export.User.Identities == nil {
+		export.User.Identities = []user.Identity{}
+	}
+
+	games, err := s.Games.FindByPlayers(ctx, []primitive.ObjectID{userID})
+	if err != nil {
+		return Export{}, err
+	}
+	for _, g := range games {
+		export.Games = append(export.Games, exportGame(g, userID))
+	}
+
+	if export.Chat, err = s.Messages.FindByUser(ctx, userID); err != nil {
+		return Export{}, err
+	}
+	if export.Chat == nil {
+		export.Chat = []chat.Message{}
+	}
+
+	for _, status := range []string{friends.StatusAccepted, friends.StatusPending} {
+		friendships, err := s.Friends.FindByUser(ctx, userID, status)
+		if err != nil {
+			return Export{}, err
+		}
+		for _, f := range friendships {
+			export.Friends = append(export.Friends, ExportFriend{
+				UserID:    f.Other(userID).Hex(),
+				Status:    f.Status,
+				Requested: f.Requester == userID,
+				CreatedAt: f.CreatedAt,
+			})
+		}
+	}
+
+	devices, err := s.Devices.FindByUser(ctx, userID)
+	if err != nil {
+		return Export{}, err
+	}
+	for _, d := range devices {
+		export.Devices = append(export.Devices, ExportDevice{Platform: d.Platform, CreatedAt: d.CreatedAt})
+	}
+
+	if export.NotificationSettings, err = s.Settings.GetSettings(ctx, userID); err != nil {
+		return Export{}, err
+	}
+	links, err := s.ChatLinks.FindLinksByUser(ctx, userID)
+	if err != nil {
+		return Export{}, err
+	}
+	export.ChatLinks = append(export.ChatLinks, links...)
+	if export.Sessions, err = s.Tokens.Sessions(ctx, userID); err != nil {
+		return Export{}, err
+	}
+	if export.APITokens, err = s.Tokens.APITokens(ctx, userID); err != nil {
+		return Export{}, err
+	}
+	return export, nil
+}
+
+// exportGame keeps the user's own board and the events they took part in,
+// as they would see them.
+func exportGame(g game.Game, userID primitive.ObjectID) ExportGame {
+	eg := ExportGame{
+		ID:        g.ID.Hex(),
+		Status:    g.Status,
+		CreatedAt: g.CreatedAt,
+		Host:      g.Host == userID,
+		Rules:     g.Rules,
+		Events:    []game.Event{},
+		Disputes:  []ExportDispute{},
+	}
+	for _, p := range g.PlayerStates {
+		if p.User != userID {
+			continue
+		}
+		eg.Player = ExportPlayer{
+			Board:          []ExportTile{},
+			Cooties:        p.Cooties,
+			LastAction:     p.LastAction,
+			ClaimedCount:   p.ClaimedCount,
+			CorrectGuesses: p.CorrectGuesses,
+			Streak:         p.Streak,
+			Items:          p.Items,
+		}
+		for _, t := range p.Board {
+			eg.Player.Board = append(eg.Player.Board, ExportTile{FriendID: t.FriendID.Hex(), Claimed: t.Claimed})
+		}
+	}
+	for _, ev := range g.Events {
+		if ev.Actor != userID && ev.Target != userID {
+			continue
+		}
+		if visible, ok := ev.RedactedFor(userID); ok {
+			eg.Events = append(eg.Events, visible)
+		}
+	}
+	for _, d := range g.Disputes {
+		if d.Disputer == userID {
+			eg.Disputes = append(eg.Disputes, ExportDispute{Event: d.Event, Reason: d.Reason, Status: d.Status, CreatedAt: d.CreatedAt})
+		}
+	}
+	return eg
+}
+
+// DeleteAccountHandler godoc
+// @Summary Delete your account
+// @Description Permanently delete the current user. Past games keep their boards and history under a placeholder name; personal data, chat messages, friends, devices, sessions, tokens, password resets and failed login records are removed.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param confirm body DeleteAccountRequest true "Username, to confirm"
+// @Success 200 {object} map[string]string
+// @Router /users/me [delete]
+// @Security BearerAuth
+func DeleteAccountHandler(s Stores) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userObjID, ok := auth.CurrentUser(c)
+		if !ok {
+			return
+		}
+
+		var req DeleteAccountRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		u, err := s.Users.FindUserWithID(c.Request.Context(), userObjID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if req.Confirm != u.Username {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "confirm with your username"})
+			return
+		}
+
+		if err := s.delete(c.Request.Context(), u); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "deleted"})
+	}
+}
+
+// delete anonymizes the user in their games, then removes everything else.
+// The user document goes last so a failed deletion can be retried.
+func (s Stores) delete(ctx context.Context, u user.User) error {
+	if err := s.Tokens.Forget(ctx, u.ID); err != nil {
+		return err
+	}
+	if err := s.Resets.RemoveUser(ctx, u.ID); err != nil {
+		return err
+	}
+	if err := s.Logins.Forget(ctx, u.Username); err != nil {
+		return err
+	}
+
+	games, err := s.Games.FindByPlayers(ctx, []primitive.ObjectID{u.ID})
+	if err != nil {
+		return err
+	}
+	gameIDs := make([]primitive.ObjectID, 0, len(games))
+	for _, g := range games {
+		err := game.Save(ctx, s.Games, &g, func(g *game.Game) bool {
+			g.Anonymize(u.ID)
+			return true
+		})
+		if err != nil {
+			return err
+		}
+		gameIDs = append(gameIDs, g.ID)
+	}
+
+	if err := s.Messages.RemoveUser(ctx, u.ID, gameIDs); err != nil {
+		return err
+	}
+	if err := s.Devices.RemoveUser(ctx, u.ID); err != nil {
+		return err
+	}
+	if err := s.Settings.DeleteSettings(ctx, u.ID); err != nil {
+		return err
+	}
+	if err := s.ChatLinks.UnlinkUser(ctx, u.ID); err != nil {
+		return err
+	}
+	if err := s.Friends.DeleteByUser(ctx, u.ID); err != nil {
+		return err
+	}
+	user.DeleteAvatar(ctx, s.Avatars, u.Profile.Avatar)
+
+	if err := s.Users.DeleteUser(ctx, u.ID); err != nil {
+		return err
+	}
+	log.Println("Deleted account", u.ID.Hex())
+	return nil
+}
@@ -0,0 +1,78 @@
+package account
+
+import (
+	"irl-mafia-game/auth"
+	"irl-mafia-game/chat"
+	"irl-mafia-game/chatbot"
+	"irl-mafia-game/game"
+	"irl-mafia-game/notifications"
+	"irl-mafia-game/user"
+	"time"
+)
+
+// Export is everything stored about one user.
+type Export struct {
+	ExportedAt           time.Time              `json:"exportedAt"`
+	User                 ExportUser             `json:"user"`
+	Games                []ExportGame           `json:"games"`
+	Chat                 []chat.Message         `json:"chat"`
+	Friends              []ExportFriend         `json:"friends"`
+	Devices              []ExportDevice         `json:"devices"`
+	NotificationSettings notifications.Settings `json:"notificationSettings"`
+	ChatLinks            []chatbot.Link         `json:"chatLinks"`
+	Sessions             []auth.Session         `json:"sessions"`
+	APITokens            []auth.APIToken        `json:"apiTokens"`
+}
+
+type ExportUser struct {
+	ID         string               `json:"id"`
+	Username   string               `json:"username"`
+	Email      string               `json:"email,omitempty"`
+	Profile    user.ProfileResponse `json:"profile"`
+	Identities []user.Identity      `json:"identities"`
+}
+
+type ExportGame struct {
+	ID        string          `json:"id"`
+	Status    string          `json:"status"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Host      bool            `json:"host"`
+	Rules     game.Rules      `json:"rules"`
+	Player    ExportPlayer    `json:"player"`
+	Events    []game.Event    `json:"events"`   // events the user took part in
+	Disputes  []ExportDispute `json:"disputes"` // disputes the user opened
+}
+
+type ExportDispute struct {
+	Event     int       `json:"event"`
+	Reason    string    `json:"reason"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type ExportPlayer struct {
+	Board          []ExportTile `json:"board"`
+	Cooties        bool         `json:"cooties"`
+	LastAction     time.Time    `json:"lastAction"`
+	ClaimedCount   int          `json:"claimedCount"`
+	CorrectGuesses int          `json:"correctGuesses"`
+	Streak         int          `json:"streak"`
+	Items          []string     `json:"items"`
+}
+
+type ExportTile struct {
+	FriendID string `json:"friendId"`
+	Claimed  bool   `json:"claimed"`
+}
+
+type ExportFriend struct {
+	UserID    string    `json:"userId"`
+	Status    string    `json:"status"`
+	Requested bool      `json:"requested"` // the user sent the request
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type ExportDevice struct {
+	Platform  string    `json:"platform"`
+	CreatedAt time.Time `json:"createdAt"`
+}
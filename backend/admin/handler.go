@@ -0,0 +1,296 @@
+package admin
+
+import (
+	"irl-mafia-game/auth"
+	"irl-mafia-game/game"
+	"irl-mafia-game/user"
+	"net/http"
+	"runtime"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const defaultPageSize = 50
+
+// ListUsersHandler godoc
+// @Summary List users
+// @Description Page through all users, optionally searching by username, display name or email
+// @Tags admin
+// @Produce json
+// @Param q query string false "Search text"
+// @Param skip query int false "Users to skip"
+// @Param limit query int false "Page size, at most 100"
+// @Success 200 {object} UserPage
+// @Router /admin/users [get]
+// @Security BearerAuth
+func ListUsersHandler(users user.UserRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var q ListUsersQuery
+		if err := c.ShouldBindQuery(&q); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if q.Limit == 0 {
+			q.Limit = defaultPageSize
+		}
+
+		found, total, err := users.SearchUsers(c.Request.Context(), q.Query, q.Skip, q.Limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		page := UserPage{Users: make([]UserSummary, len(found)), Total: total}
+		for i, u := range found {
+			page.Users[i] = summarize(u)
+		}
+		c.JSON(http.StatusOK, page)
+	}
+}
+
+// SetDisabledHandler godoc
+// @Summary Disable or enable an account
+// @Description Disabling blocks logins, signs the user out of every session and deletes their API tokens; enabling lets them log in again
+// @Tags admin
+// @Produce json
+// @Param userId path string true "User ID"
+// @Success 200 {object} UserSummary
+// @Router /admin/users/{userId}/disable [post]
+// @Router /admin/users/{userId}/enable [post]
+// @Security BearerAuth
+func SetDisabledHandler(users user.UserRepository, tokens *auth.Tokens, disabled bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		adminID, ok := auth.CurrentUser(c)
+		if !ok {
+			return
+		}
+		target, ok := loadUser(c, users)
+		if !ok {
+			return
+		}
+		if disabled && target.ID == adminID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "you can't disable your own account"})
+			return
+		}
+
+		if err := users.SetDisabled(c.Request.Context(), target.ID, disabled); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if disabled {
+			if err := tokens.LogoutAll(c.Request.Context(), target.ID); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			if err := tokens.RevokeAPITokens(c.Request.Context(), target.ID); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		target.Disabled = disabled
+		c.JSON(http.StatusOK, summarize(target))
+	}
+}
+
+// SetRolesHandler godoc
+// @Summary Set a user's roles
+// @Description Replace the roles of a user
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param userId path string true "User ID"
+// @Param roles body SetRolesRequest true "Roles"
+// @Success 200 {object} UserSummary
+// @Router /admin/users/{userId}/roles [put]
+// @Security BearerAuth
+func SetRolesHandler(users user.UserRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		adminID, ok := auth.CurrentUser(c)
+		if !ok {
+			return
+		}
+		var req SetRolesRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		for _, role := range req.Roles {
+			if !slices.Contains(user.KnownRoles, role) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "unknown role: " + role})
+				return
+			}
+		}
+		roles := slices.Compact(slices.Sorted(slices.Values(req.Roles)))
+
+		target, ok := loadUser(c, users)
+		if !ok {
+			return
+		}
+		if target.ID == adminID && !slices.Contains(roles, user.RoleAdmin) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "you can't remove your own admin role"})
+			return
+		}
+
+		if err := users.SetRoles(c.Request.Context(), target.ID, roles); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		target.Roles = roles
+		c.JSON(http.StatusOK, summarize(target))
+	}
+}
+
+// GetGameStateHandler godoc
+// @Summary Inspect a game
+// @Description Retrieve a game's full state, including everything hidden from players
+// @Tags admin
+// @Produce json
+// @Param id path string true "Game ID"
+// @Success 200 {object} GameState
+// @Router /admin/games/{id} [get]
+// @Security BearerAuth
+func GetGameStateHandler(games game.GameRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		g, ok := game.FindGame(c, games)
+		if !ok {
+			return
+		}
+		c.JSON(http.StatusOK, GameState{Game: g, Standings: g.Standings()})
+	}
+}
+
+// GetGameReportHandler godoc
+// @Summary Get a game's claim abuse report
+// @Description See suspicious claim patterns involving a game's players, as its host would
+// @Tags admin
+// @Produce json
+// @Param id path string true "Game ID"
+// @Success 200 {array} game.Flag
+// @Router /admin/games/{id}/report [get]
+// @Security BearerAuth
+func GetGameReportHandler(games game.GameRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		g, ok := game.FindGame(c, games)
+		if !ok {
+			return
+		}
+		flags, err := game.Report(c.Request.Context(), games, g)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, flags)
+	}
+}
+
+// FinishGameHandler godoc
+// @Summary Force-finish a game
+// @Description End an active game now, with the given winner or the current leader
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Game ID"
+// @Param request body FinishGameRequest false "Winner"
+// @Success 200 {object} GameState
+// @Router /admin/games/{id}/finish [post]
+// @Security BearerAuth
+func FinishGameHandler(games game.GameRepository, engine *game.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req FinishGameRequest
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+		var winner primitive.ObjectID
+		if req.WinnerID != "" {
+			var err error
+			if winner, err = primitive.ObjectIDFromHex(req.WinnerID); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid winner ID"})
+				return
+			}
+		}
+
+		g, ok := game.FindGame(c, games)
+		if !ok {
+			return
+		}
+		var finishErr error
+		err := game.Save(c.Request.Context(), games, &g, func(g *game.Game) bool {
+			finishErr = engine.ForceFinish(g, winner)
+			return finishErr == nil
+		})
+		if finishErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": finishErr.Error()})
+			return
+		}
+		if err == game.ErrConflict {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, GameState{Game: g, Standings: g.Standings()})
+	}
+}
+
+// StatsHandler godoc
+// @Summary System stats
+// @Description Counts of users and games, and the health of this server
+// @Tags admin
+// @Produce json
+// @Success 200 {object} Stats
+// @Router /admin/stats [get]
+// @Security BearerAuth
+func StatsHandler(users user.UserRepository, games game.GameRepository, started time.Time) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userStats, err := users.Stats(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		gameStats, err := games.CountByStatus(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		var mem runtime.MemStats
+		runtime.ReadMemStats(&mem)
+		c.JSON(http.StatusOK, Stats{
+			Users:       userStats,
+			Games:       gameStats,
+			Uptime:      time.Since(started).Round(time.Second).String(),
+			Goroutines:  runtime.NumGoroutine(),
+			HeapAllocMB: float64(mem.HeapAlloc) / (1 << 20),
+		})
+	}
+}
+
+func loadUser(c *gin.Context, users user.UserRepository) (user.User, bool) {
+	userObjID, err := primitive.ObjectIDFromHex(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return user.User{}, false
+	}
+	u, err := users.FindUserWithID(c.Request.Context(), userObjID)
+	if err == mongo.ErrNoDocuments {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return user.User{}, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return user.User{}, false
+	}
+	return u, true
+}
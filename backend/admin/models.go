@@ -0,0 +1,67 @@
+package admin
+
+import (
+	"irl-mafia-game/game"
+	"irl-mafia-game/user"
+)
+
+type ListUsersQuery struct {
+	Query string `form:"q"`
+	Skip  int64  `form:"skip" binding:"min=0"`
+	Limit int64  `form:"limit" binding:"min=0,max=100"`
+}
+
+// UserSummary is what administrators see about an account.
+type UserSummary struct {
+	ID         string               `json:"id"`
+	Username   string               `json:"username"`
+	Email      string               `json:"email,omitempty"`
+	Roles      []string             `json:"roles,omitempty"`
+	Disabled   bool                 `json:"disabled"`
+	Guest      bool                 `json:"guest,omitempty"`
+	Games      int                  `json:"games"`
+	Identities []user.Identity      `json:"identities,omitempty"`
+	Profile    user.ProfileResponse `json:"profile"`
+}
+
+type UserPage struct {
+	Users []UserSummary `json:"users"`
+	Total int64         `json:"total"`
+}
+
+type SetRolesRequest struct {
+	Roles []string `json:"roles"`
+}
+
+type FinishGameRequest struct {
+	WinnerID string `json:"winnerId"` // defaults to the current leader
+}
+
+// GameState is a game with nothing hidden, including every board, item
+// and the Cooties holder.
+type GameState struct {
+	Game      game.Game       `json:"game"`
+	Standings []game.Standing `json:"standings"`
+}
+
+type Stats struct {
+	Users       user.UserStats   `json:"users"`
+	Games       map[string]int64 `json:"games"` // by status
+	Uptime      string           `json:"uptime"`
+	Goroutines  int              `json:"goroutines"`
+	HeapAllocMB float64          `json:"heapAllocMb"`
+}
+
+func summarize(u user.User) UserSummary {
+	return UserSummary{
+		ID:         u.ID.Hex(),
+		Username:   u.Username,
+		Email:      u.Email,
+		Roles:      u.Roles,
+		Disabled:   u.Disabled,
+		Guest:      u.Guest,
+		Games:      len(u.Games),
+		Identities: u.Identities,
+		Profile:    u.Profile.Response(),
+	}
+}
@@ -0,0 +1,207 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Scopes limit what an API token can do. Tokens from logging in have
+// every scope.
+const (
+	ScopeRead  = "read"  // view games, profiles and settings
+	ScopePlay  = "play"  // everything read allows, plus taking part in games
+	ScopeAdmin = "admin" // the /admin API, for administrators only
+)
+
+var Scopes = []string{ScopeRead, ScopePlay, ScopeAdmin}
+
+// APITokenPrefix starts every API token, which tells them apart from JWTs.
+const APITokenPrefix = "imt_"
+
+const (
+	maxAPITokens    = 20
+	apiTokenTouchAt = time.Minute // how stale LastUsed may get
+)
+
+var (
+	ErrInvalidAPIToken  = errors.New("invalid API token")
+	ErrNoSuchAPIToken   = errors.New("API token not found")
+	ErrTooManyAPITokens = errors.New("too many API tokens, delete one first")
+)
+
+// APIToken is a long-lived token a user creates for scripts. It is stored
+// by hash only; the Prefix helps its owner recognise it.
+type APIToken struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID    primitive.ObjectID `bson:"userId" json:"-"`
+	Name      string             `bson:"name" json:"name"`
+	Prefix    string             `bson:"prefix" json:"prefix"`
+	Hash      string             `bson:"hash" json:"-"`
+	Scopes    []string           `bson:"scopes" json:"scopes"`
+	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
+	ExpiresAt time.Time          `bson:"expiresAt" json:"expiresAt"`
+	LastUsed  time.Time          `bson:"lastUsed,omitempty" json:"lastUsed,omitempty"`
+}
+
+// HasScope reports whether the token allows something needing scope.
+func (t APIToken) HasScope(scope string) bool {
+	return HasScope(t.Scopes, scope)
+}
+
+// HasScope reports whether scopes allow something needing scope. Play
+// includes read.
+func HasScope(scopes []string, scope string) bool {
+	return slices.Contains(scopes, scope) || (scope == ScopeRead && slices.Contains(scopes, ScopePlay))
+}
+
+type APITokenRepository interface {
+	Add(ctx context.Context, t APIToken) (primitive.ObjectID, error)
+	FindByHash(ctx context.Context, hash string) (APIToken, error)
+	FindByUser(ctx context.Context, userID primitive.ObjectID) ([]APIToken, error)
+	Touch(ctx context.Context, id primitive.ObjectID, used time.Time) error
+	Delete(ctx context.Context, id, userID primitive.ObjectID) error
+	RemoveUser(ctx context.Context, userID primitive.ObjectID) error
+}
+
+type mongoAPITokenRepository struct {
+	col *mongo.Collection
+}
+
+func NewMongoAPITokenRepository(col *mongo.Collection) APITokenRepository {
+	return &mongoAPITokenRepository{col: col}
+}
+
+func (r *mongoAPITokenRepository) Add(ctx context.Context, t APIToken) (primitive.ObjectID, error) {
+	res, err := r.col.InsertOne(ctx, t)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	return res.InsertedID.(primitive.ObjectID), nil
+}
+
+func (r *mongoAPITokenRepository) FindByHash(ctx context.Context, hash string) (APIToken, error) {
+	var t APIToken
+	err := r.col.FindOne(ctx, bson.M{"hash": hash}).Decode(&t)
+	return t, err
+}
+
+func (r *mongoAPITokenRepository) FindByUser(ctx context.Context, userID primitive.ObjectID) ([]APIToken, error) {
+	cursor, err := r.col.Find(ctx, bson.M{"userId": userID}, options.Find().SetSort(bson.M{"createdAt": -1}))
+	if err != nil {
+		return nil, err
+	}
+	tokens := []APIToken{}
+	if err := cursor.All(ctx, &tokens); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (r *mongoAPITokenRepository) Touch(ctx context.Context, id primitive.ObjectID, used time.Time) error {
+	_, err := r.col.UpdateByID(ctx, id, bson.M{"$set": bson.M{"lastUsed": used}})
+	return err
+}
+
+func (r *mongoAPITokenRepository) Delete(ctx context.Context, id, userID primitive.ObjectID) error {
+	result, err := r.col.DeleteOne(ctx, bson.M{"_id": id, "userId": userID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *mongoAPITokenRepository) RemoveUser(ctx context.Context, userID primitive.ObjectID) error {
+	_, err := r.col.DeleteMany(ctx, bson.M{"userId": userID})
+	return err
+}
+
+// CreateAPIToken makes a new API token and returns it with the raw token,
+// which is only ever shown this once.
+func (t *Tokens) CreateAPIToken(ctx context.Context, userID primitive.ObjectID, name string, scopes []string, ttl time.Duration) (APIToken, string, error) {
+	existing, err := t.apiTokens.FindByUser(ctx, userID)
+	if err != nil {
+		return APIToken{}, "", err
+	}
+	if len(existing) >= maxAPITokens {
+		return APIToken{}, "", ErrTooManyAPITokens
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return APIToken{}, "", err
+	}
+	raw := APITokenPrefix + base64.RawURLEncoding.EncodeToString(buf)
+
+	now := t.now()
+	token := APIToken{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    raw[:len(APITokenPrefix)+6],
+		Hash:      hashToken(raw),
+		Scopes:    scopes,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+	if token.ID, err = t.apiTokens.Add(ctx, token); err != nil {
+		return APIToken{}, "", err
+	}
+	return token, raw, nil
+}
+
+// APITokens returns a user's API tokens, expired ones included until
+// they are cleaned up.
+func (t *Tokens) APITokens(ctx context.Context, userID primitive.ObjectID) ([]APIToken, error) {
+	return t.apiTokens.FindByUser(ctx, userID)
+}
+
+func (t *Tokens) DeleteAPIToken(ctx context.Context, userID, id primitive.ObjectID) error {
+	err := t.apiTokens.Delete(ctx, id, userID)
+	if err == mongo.ErrNoDocuments {
+		return ErrNoSuchAPIToken
+	}
+	return err
+}
+
+// RevokeAPITokens deletes every API token of a user.
+func (t *Tokens) RevokeAPITokens(ctx context.Context, userID primitive.ObjectID) error {
+	return t.apiTokens.RemoveUser(ctx, userID)
+}
+
+// VerifyAPIToken looks up a raw API token, recording that it was used.
+func (t *Tokens) VerifyAPIToken(ctx context.Context, raw string) (APIToken, error) {
+	if !strings.HasPrefix(raw, APITokenPrefix) {
+		return APIToken{}, ErrInvalidAPIToken
+	}
+	token, err := t.apiTokens.FindByHash(ctx, hashToken(raw))
+	if err == mongo.ErrNoDocuments {
+		return APIToken{}, ErrInvalidAPIToken
+	}
+	if err != nil {
+		return APIToken{}, err
+	}
+
+	now := t.now()
+	if !now.Before(token.ExpiresAt) {
+		return APIToken{}, ErrInvalidAPIToken
+	}
+	if now.Sub(token.LastUsed) > apiTokenTouchAt {
+		if err := t.apiTokens.Touch(ctx, token.ID, now); err != nil {
+			return APIToken{}, err
+		}
+		token.LastUsed = now
+	}
+	return token, nil
+}
@@ -0,0 +1,303 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Attempts counts recent failed logins for one username or IP.
+type Attempts struct {
+	Key         string    `bson:"_id"`
+	Failures    int       `bson:"failures"`
+	LastFailure time.Time `bson:"lastFailure"`
+}
+
+// AttemptStore keeps failure counts. Failures older than the policy's
+// window no longer count.
+type AttemptStore interface {
+	Get(ctx context.Context, key string, now time.Time, window time.Duration) (Attempts, error)
+	// Attempt counts one more failure unless the key is blocked, and
+	// returns the recent failures before it, in one step.
+	Attempt(ctx context.Context, key string, now time.Time, policy Policy) (Attempts, error)
+	// Forgive takes back one failure.
+	Forgive(ctx context.Context, key string) error
+	Reset(ctx context.Context, key string) error
+}
+
+// FailedLogin is an audit record of a rejected login.
+type FailedLogin struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Username  string             `bson:"username" json:"username"`
+	IP        string             `bson:"ip" json:"ip"`
+	UserAgent string             `bson:"userAgent" json:"userAgent"`
+	Reason    string             `bson:"reason" json:"reason"` // invalid_credentials, locked
+	At        time.Time          `bson:"at" json:"at"`
+}
+
+type AuditLog interface {
+	RecordFailedLogin(ctx context.Context, f FailedLogin) error
+	RemoveUsername(ctx context.Context, username string) error
+}
+
+// Policy sets how quickly repeated failures slow down and then lock out
+// logins.
+type Policy struct {
+	FreeAttempts int           // failures allowed before any delay
+	BaseDelay    time.Duration // delay after the first counted failure, doubling after each
+	MaxDelay     time.Duration
+	LockoutAfter int // failures that lock the key out entirely
+	Lockout      time.Duration
+	Window       time.Duration // how long a failure counts for
+}
+
+var (
+	UserPolicy = Policy{FreeAttempts: 3, BaseDelay: time.Second, MaxDelay: 5 * time.Minute, LockoutAfter: 10, Lockout: 15 * time.Minute, Window: time.Hour}
+	// Many people can share an IP, so it gets more room
+	IPPolicy = Policy{FreeAttempts: 20, BaseDelay: time.Second, MaxDelay: 5 * time.Minute, LockoutAfter: 100, Lockout: 15 * time.Minute, Window: time.Hour}
+)
+
+// blockedUntil is when the next attempt is allowed after a.
+func (p Policy) blockedUntil(a Attempts) time.Time {
+	switch {
+	case a.Failures < p.FreeAttempts:
+		return time.Time{}
+	case a.Failures >= p.LockoutAfter:
+		return a.LastFailure.Add(p.Lockout)
+	}
+	delay := p.BaseDelay << min(a.Failures-p.FreeAttempts, 30)
+	if delay > p.MaxDelay || delay <= 0 {
+		delay = p.MaxDelay
+	}
+	return a.LastFailure.Add(delay)
+}
+
+// LoginGuard throttles password checks per username and per IP.
+type LoginGuard struct {
+	store AttemptStore
+	audit AuditLog
+	now   func() time.Time
+}
+
+func NewLoginGuard(store AttemptStore, audit AuditLog) *LoginGuard {
+	return &LoginGuard{store: store, audit: audit, now: time.Now}
+}
+
+// Attempt counts a login attempt as failed before the password is
+// checked, so parallel guesses can't all get past the limits, and returns
+// how long the caller must wait, or zero if they may try now. Attempts
+// refused while blocked aren't counted, or retrying would keep a user
+// locked out for good.
+func (g *LoginGuard) Attempt(ctx context.Context, username, ip string) (time.Duration, error) {
+	now := g.now()
+	keys := g.keys(username, ip)
+
+	// Check every key first so a blocked IP doesn't count against the
+	// username, or the other way round
+	wait, err := g.wait(keys, now, func(key string, policy Policy) (Attempts, error) {
+		return g.store.Get(ctx, key, now, policy.Window)
+	})
+	if err != nil || wait > 0 {
+		return wait, err
+	}
+	return g.wait(keys, now, func(key string, policy Policy) (Attempts, error) {
+		return g.store.Attempt(ctx, key, now, policy)
+	})
+}
+
+// wait returns the longest time any of the keys is blocked for, as
+// attempts reports them.
+func (g *LoginGuard) wait(keys map[string]Policy, now time.Time, attempts func(string, Policy) (Attempts, error)) (time.Duration, error) {
+	var wait time.Duration
+	for key, policy := range keys {
+		a, err := attempts(key, policy)
+		if err != nil {
+			return 0, err
+		}
+		if until := policy.blockedUntil(a); until.After(now) {
+			wait = max(wait, until.Sub(now))
+		}
+	}
+	return wait, nil
+}
+
+// Fail audits a rejected login. Attempt already counted it.
+func (g *LoginGuard) Fail(ctx context.Context, username, ip, userAgent, reason string) error {
+	return g.audit.RecordFailedLogin(ctx, FailedLogin{Username: username, IP: ip, UserAgent: userAgent, Reason: reason, At: g.now()})
+}
+
+// Succeed clears the username's failures and takes back the attempt from
+// the IP. The IP's earlier failures stay, so one good account can't be
+// used to reset guessing at others.
+func (g *LoginGuard) Succeed(ctx context.Context, username, ip string) error {
+	if err := g.store.Reset(ctx, "user:"+username); err != nil {
+		return err
+	}
+	return g.store.Forgive(ctx, "ip:"+ip)
+}
+
+// Forget drops a username's failures and audit records, for when its
+// account is deleted.
+func (g *LoginGuard) Forget(ctx context.Context, username string) error {
+	if err := g.store.Reset(ctx, "user:"+username); err != nil {
+		return err
+	}
+	return g.audit.RemoveUsername(ctx, username)
+}
+
+func (g *LoginGuard) keys(username, ip string) map[string]Policy {
+	return map[string]Policy{"user:" + username: UserPolicy, "ip:" + ip: IPPolicy}
+}
+
+// MemoryAttemptStore keeps attempts in process, for a single instance.
+type MemoryAttemptStore struct {
+	mu       sync.Mutex
+	attempts map[string]Attempts
+}
+
+func NewMemoryAttemptStore() *MemoryAttemptStore {
+	return &MemoryAttemptStore{attempts: make(map[string]Attempts)}
+}
+
+func (s *MemoryAttemptStore) Get(ctx context.Context, key string, now time.Time, window time.Duration) (Attempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.get(key, now, window), nil
+}
+
+func (s *MemoryAttemptStore) get(key string, now time.Time, window time.Duration) Attempts {
+	a := s.attempts[key]
+	if now.Sub(a.LastFailure) > window {
+		return Attempts{Key: key}
+	}
+	return a
+}
+
+func (s *MemoryAttemptStore) Attempt(ctx context.Context, key string, now time.Time, policy Policy) (Attempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	before := s.get(key, now, policy.Window)
+	if policy.blockedUntil(before).After(now) {
+		return before, nil
+	}
+	a := before
+	a.Failures++
+	a.LastFailure = now
+	s.attempts[key] = a
+
+	// Drop stale entries now and then so the map doesn't grow forever
+	if len(s.attempts) > 10000 {
+		for k, old := range s.attempts {
+			if now.Sub(old.LastFailure) > 24*time.Hour {
+				delete(s.attempts, k)
+			}
+		}
+	}
+	return before, nil
+}
+
+func (s *MemoryAttemptStore) Forgive(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if a, ok := s.attempts[key]; ok && a.Failures > 0 {
+		a.Failures--
+		s.attempts[key] = a
+	}
+	return nil
+}
+
+func (s *MemoryAttemptStore) Reset(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.attempts, key)
+	return nil
+}
+
+type mongoAttemptStore struct {
+	col *mongo.Collection
+}
+
+// NewMongoAttemptStore shares attempts between instances.
+func NewMongoAttemptStore(col *mongo.Collection) AttemptStore {
+	return &mongoAttemptStore{col: col}
+}
+
+func (s *mongoAttemptStore) Get(ctx context.Context, key string, now time.Time, window time.Duration) (Attempts, error) {
+	var a Attempts
+	err := s.col.FindOne(ctx, bson.M{"_id": key, "lastFailure": bson.M{"$gte": now.Add(-window)}}).Decode(&a)
+	if err == mongo.ErrNoDocuments {
+		return Attempts{Key: key}, nil
+	}
+	return a, err
+}
+
+func (s *mongoAttemptStore) Attempt(ctx context.Context, key string, now time.Time, policy Policy) (Attempts, error) {
+	for try := 0; try < 5; try++ {
+		before, err := s.Get(ctx, key, now, policy.Window)
+		if err != nil || policy.blockedUntil(before).After(now) {
+			return before, err
+		}
+
+		if before.LastFailure.IsZero() {
+			// Start counting again. If another attempt got there first
+			// the insert clashes and we look again
+			_, err = s.col.UpdateOne(ctx,
+				bson.M{"_id": key, "lastFailure": bson.M{"$lt": now.Add(-policy.Window)}},
+				bson.M{"$set": bson.M{"failures": 1, "lastFailure": now}},
+				options.Update().SetUpsert(true),
+			)
+			if !mongo.IsDuplicateKeyError(err) {
+				return before, err
+			}
+			continue
+		}
+
+		// Count on top of the failures we saw, unless they changed since
+		res, err := s.col.UpdateOne(ctx,
+			bson.M{"_id": key, "failures": before.Failures, "lastFailure": before.LastFailure},
+			bson.M{"$inc": bson.M{"failures": 1}, "$set": bson.M{"lastFailure": now}},
+		)
+		if err != nil || res.MatchedCount == 1 {
+			return before, err
+		}
+	}
+	return Attempts{}, errors.New("login attempts are too busy to count")
+}
+
+func (s *mongoAttemptStore) Forgive(ctx context.Context, key string) error {
+	_, err := s.col.UpdateOne(ctx,
+		bson.M{"_id": key, "failures": bson.M{"$gt": 0}},
+		bson.M{"$inc": bson.M{"failures": -1}},
+	)
+	return err
+}
+
+func (s *mongoAttemptStore) Reset(ctx context.Context, key string) error {
+	_, err := s.col.DeleteOne(ctx, bson.M{"_id": key})
+	return err
+}
+
+type mongoAuditLog struct {
+	col *mongo.Collection
+}
+
+func NewMongoAuditLog(col *mongo.Collection) AuditLog {
+	return &mongoAuditLog{col: col}
+}
+
+func (l *mongoAuditLog) RecordFailedLogin(ctx context.Context, f FailedLogin) error {
+	_, err := l.col.InsertOne(ctx, f)
+	return err
+}
+
+func (l *mongoAuditLog) RemoveUsername(ctx context.Context, username string) error {
+	_, err := l.col.DeleteMany(ctx, bson.M{"username": username})
+	return err
+}
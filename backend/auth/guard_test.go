@@ -0,0 +1,129 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+type discardAudit struct{}
+
+func (discardAudit) RecordFailedLogin(ctx context.Context, f FailedLogin) error { return nil }
+
+func (discardAudit) RemoveUsername(ctx context.Context, username string) error { return nil }
+
+func TestLoginGuardParallelAttempts(t *testing.T) {
+	guard := NewLoginGuard(NewMemoryAttemptStore(), discardAudit{})
+	now := time.Now()
+	guard.now = func() time.Time { return now }
+
+	var mu sync.Mutex
+	allowed := 0
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			wait, err := guard.Attempt(context.Background(), "bob", "192.0.2.1")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if wait == 0 {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if allowed != UserPolicy.FreeAttempts {
+		t.Errorf("%d parallel attempts got through, want %d", allowed, UserPolicy.FreeAttempts)
+	}
+}
+
+func TestLoginGuardSucceed(t *testing.T) {
+	store := NewMemoryAttemptStore()
+	guard := NewLoginGuard(store, discardAudit{})
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := guard.Attempt(ctx, "bob", "192.0.2.1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := guard.Succeed(ctx, "bob", "192.0.2.1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if a := store.attempts["user:bob"]; a.Failures != 0 {
+		t.Errorf("user failures = %d, want reset", a.Failures)
+	}
+	// The successful attempt is taken back, the failed one before it stays
+	if a := store.attempts["ip:192.0.2.1"]; a.Failures != 1 {
+		t.Errorf("IP failures = %d, want 1", a.Failures)
+	}
+}
+
+// lockOut fails logins for bob, waiting out each delay, until he is
+// locked out.
+func lockOut(t *testing.T, guard *LoginGuard, now *time.Time) {
+	t.Helper()
+	for i := 0; i < UserPolicy.LockoutAfter; i++ {
+		wait, err := guard.Attempt(context.Background(), "bob", "192.0.2.1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if wait > 0 {
+			t.Fatalf("attempt %d has to wait %v", i+1, wait)
+		}
+		*now = now.Add(UserPolicy.MaxDelay)
+	}
+}
+
+func TestLoginGuardWindow(t *testing.T) {
+	guard := NewLoginGuard(NewMemoryAttemptStore(), discardAudit{})
+	now := time.Now()
+	guard.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	lockOut(t, guard, &now)
+	wait, _ := guard.Attempt(ctx, "bob", "192.0.2.1")
+	if want := UserPolicy.Lockout - UserPolicy.MaxDelay; wait != want {
+		t.Errorf("wait = %v, want the rest of the %v lockout", wait, UserPolicy.Lockout)
+	}
+
+	now = now.Add(UserPolicy.Window + time.Second)
+	if wait, _ := guard.Attempt(ctx, "bob", "192.0.2.1"); wait != 0 {
+		t.Errorf("wait = %v after the window, want 0", wait)
+	}
+}
+
+func TestLoginGuardLockoutNotExtended(t *testing.T) {
+	store := NewMemoryAttemptStore()
+	guard := NewLoginGuard(store, discardAudit{})
+	now := time.Now()
+	guard.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	lockOut(t, guard, &now)
+	lockedAt := store.attempts["user:bob"]
+
+	// Retrying all through the lockout is refused without counting
+	unlocked := lockedAt.LastFailure.Add(UserPolicy.Lockout)
+	for ; now.Before(unlocked); now = now.Add(time.Minute) {
+		if wait, _ := guard.Attempt(ctx, "bob", "192.0.2.1"); wait == 0 {
+			t.Fatalf("attempt %v into the lockout got through", now.Sub(lockedAt.LastFailure))
+		}
+	}
+	if a := store.attempts["user:bob"]; a != lockedAt {
+		t.Errorf("attempts = %+v during the lockout, want %+v", a, lockedAt)
+	}
+
+	now = unlocked
+	if wait, _ := guard.Attempt(ctx, "bob", "192.0.2.1"); wait != 0 {
+		t.Errorf("wait = %v once the lockout is over, want 0", wait)
+	}
+}
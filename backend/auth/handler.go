@@ -0,0 +1,293 @@
+package auth
+
+import (
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// JWKSHandler godoc
+// @Summary Get the token signing keys
+// @Description Public keys other services can verify access tokens with. Symmetric keys are not listed.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} JWKS
+// @Router /.well-known/jwks.json [get]
+func JWKSHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, keys.JWKS())
+	}
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
+
+// RefreshHandler godoc
+// @Summary Refresh an access token
+// @Description Swap a refresh token for a new access token and refresh token. Each refresh token works once.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param token body RefreshRequest true "Refresh token"
+// @Success 200 {object} TokenPair
+// @Router /token/refresh [post]
+func RefreshHandler(tokens *Tokens) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req RefreshRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		pair, err := tokens.Refresh(c.Request.Context(), req.RefreshToken, ClientFrom(c))
+		if err == ErrInvalidRefreshToken || err == ErrRefreshTokenReused {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, pair)
+	}
+}
+
+// LogoutHandler godoc
+// @Summary Log out
+// @Description Revoke a refresh token and every token rotated from the same login
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param token body RefreshRequest true "Refresh token"
+// @Success 200 {object} map[string]string
+// @Router /logout [post]
+func LogoutHandler(tokens *Tokens) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req RefreshRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		err := tokens.Logout(c.Request.Context(), req.RefreshToken)
+		if err != nil && err != ErrInvalidRefreshToken {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "logged out"})
+	}
+}
+
+// LogoutAllHandler godoc
+// @Summary Log out everywhere
+// @Description Revoke every session of the current user
+// @Tags auth
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Router /logout/all [post]
+// @Security BearerAuth
+func LogoutAllHandler(tokens *Tokens) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userObjID, ok := CurrentUser(c)
+		if !ok {
+			return
+		}
+
+		if err := tokens.LogoutAll(c.Request.Context(), userObjID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "logged out everywhere"})
+	}
+}
+
+// GetSessionsHandler godoc
+// @Summary List sessions
+// @Description List the devices the current user is logged in on
+// @Tags auth
+// @Produce json
+// @Success 200 {array} SessionResponse
+// @Router /users/me/sessions [get]
+// @Security BearerAuth
+func GetSessionsHandler(tokens *Tokens) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userObjID, ok := CurrentUser(c)
+		if !ok {
+			return
+		}
+
+		sessions, err := tokens.Sessions(c.Request.Context(), userObjID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		current := c.GetString("session_id")
+		response := make([]SessionResponse, len(sessions))
+		for i, s := range sessions {
+			response[i] = SessionResponse{Session: s, Current: s.ID.Hex() == current}
+		}
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// RevokeSessionHandler godoc
+// @Summary Revoke a session
+// @Description Log out one of the current user's sessions. Its tokens stop working immediately.
+// @Tags auth
+// @Produce json
+// @Param sessionId path string true "Session ID"
+// @Success 200 {object} map[string]string
+// @Router /users/me/sessions/{sessionId} [delete]
+// @Security BearerAuth
+func RevokeSessionHandler(tokens *Tokens) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userObjID, ok := CurrentUser(c)
+		if !ok {
+			return
+		}
+		sessionID, err := primitive.ObjectIDFromHex(c.Param("sessionId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid session ID"})
+			return
+		}
+
+		err = tokens.RevokeSession(c.Request.Context(), userObjID, sessionID)
+		if err == ErrNoSuchSession {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "revoked"})
+	}
+}
+
+type CreateAPITokenRequest struct {
+	Name          string   `json:"name" binding:"required,max=100"`
+	Scopes        []string `json:"scopes" binding:"required,min=1"`       // read, play, admin
+	ExpiresInDays int      `json:"expiresInDays" binding:"min=0,max=365"` // defaults to 30
+}
+
+type CreateAPITokenResponse struct {
+	APIToken
+	Token string `json:"token"` // only shown once
+}
+
+// GetAPITokensHandler godoc
+// @Summary List API tokens
+// @Description List the current user's API tokens. The tokens themselves are never shown again.
+// @Tags auth
+// @Produce json
+// @Success 200 {array} APIToken
+// @Router /users/me/api-tokens [get]
+// @Security BearerAuth
+func GetAPITokensHandler(tokens *Tokens) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userObjID, ok := CurrentUser(c)
+		if !ok {
+			return
+		}
+
+		apiTokens, err := tokens.APITokens(c.Request.Context(), userObjID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, apiTokens)
+	}
+}
+
+// CreateAPITokenHandler godoc
+// @Summary Create an API token
+// @Description Create a scoped, expiring token for scripts and bots. Send it as a Bearer token like an access token. The admin scope is only available to administrators.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param token body CreateAPITokenRequest true "Name, scopes and lifetime"
+// @Success 200 {object} CreateAPITokenResponse
+// @Router /users/me/api-tokens [post]
+// @Security BearerAuth
+func CreateAPITokenHandler(tokens *Tokens, roles RoleLookup, adminRole string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userObjID, ok := CurrentUser(c)
+		if !ok {
+			return
+		}
+
+		var req CreateAPITokenRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		for _, scope := range req.Scopes {
+			if !slices.Contains(Scopes, scope) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "unknown scope: " + scope})
+				return
+			}
+		}
+		if slices.Contains(req.Scopes, ScopeAdmin) {
+			userRoles, err := roles(c.Request.Context(), userObjID.Hex())
+			if err != nil || !slices.Contains(userRoles, adminRole) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "only administrators can create admin tokens"})
+				return
+			}
+		}
+		days := req.ExpiresInDays
+		if days == 0 {
+			days = 30
+		}
+
+		scopes := slices.Compact(slices.Sorted(slices.Values(req.Scopes)))
+		token, raw, err := tokens.CreateAPIToken(c.Request.Context(), userObjID, req.Name, scopes, time.Duration(days)*24*time.Hour)
+		if err == ErrTooManyAPITokens {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, CreateAPITokenResponse{APIToken: token, Token: raw})
+	}
+}
+
+// DeleteAPITokenHandler godoc
+// @Summary Delete an API token
+// @Description Delete one of the current user's API tokens. It stops working immediately.
+// @Tags auth
+// @Produce json
+// @Param tokenId path string true "API token ID"
+// @Success 200 {object} map[string]string
+// @Router /users/me/api-tokens/{tokenId} [delete]
+// @Security BearerAuth
+func DeleteAPITokenHandler(tokens *Tokens) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userObjID, ok := CurrentUser(c)
+		if !ok {
+			return
+		}
+		tokenID, err := primitive.ObjectIDFromHex(c.Param("tokenId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid token ID"})
+			return
+		}
+
+		err = tokens.DeleteAPIToken(c.Request.Context(), userObjID, tokenID)
+		if err == ErrNoSuchAPIToken {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "deleted"})
+	}
+}
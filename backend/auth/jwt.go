@@ -6,30 +6,34 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
-// TODO: very important - use env var or config file in production!!!
-var jwtKey = []byte("super-secret-key") // use env var in production
+var keys *KeySet
+
+// SetKeys installs the keys tokens are signed and verified with. It must
+// be called before serving requests.
+func SetKeys(ks *KeySet) {
+	keys = ks
+}
 
 type Claims struct {
-	UserID string `json:"user_id"`
+	UserID    string `json:"user_id"`
+	SessionID string `json:"sid,omitempty"` // refresh token family the token came from
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(userID string) (string, error) {
+func GenerateToken(userID, sessionID string) (string, error) {
 	claims := &Claims{
-		UserID: userID,
+		UserID:    userID,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(AccessTokenTTL)),
 		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtKey)
+	return keys.Sign(claims)
 }
 
 func VerifyToken(tokenStr string) (*Claims, error) {
 	claims := &Claims{}
-	token, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
-		return jwtKey, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenStr, claims, keys.Keyfunc)
 	if err != nil || !token.Valid {
 		return nil, err
 	}
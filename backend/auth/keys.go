@@ -0,0 +1,246 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// minSecretLen is the shortest HS256 secret accepted, the size of the
+// hash.
+const minSecretLen = 32
+
+// Key is one signing key. Retired keys can keep only their public half
+// so tokens they signed stay valid until they expire.
+type Key struct {
+	ID      string
+	Method  jwt.SigningMethod
+	Signing any // HMAC secret or private key, nil for verify-only keys
+	Verify  any // HMAC secret or public key
+}
+
+// KeySet holds every key tokens are accepted from, and which one signs new
+// tokens.
+type KeySet struct {
+	mu      sync.RWMutex
+	keys    map[string]Key
+	current string
+}
+
+// KeyConfig describes a key in the JSON file named by JWT_KEYS_FILE.
+type KeyConfig struct {
+	ID             string `json:"kid"`
+	Alg            string `json:"alg"`            // HS256, RS256 or EdDSA
+	Secret         string `json:"secret"`         // HS256 only
+	PrivateKeyFile string `json:"privateKeyFile"` // PEM, RS256 and EdDSA
+	PublicKeyFile  string `json:"publicKeyFile"`  // PEM, for keys that no longer sign
+}
+
+type KeysConfig struct {
+	Signing string      `json:"signing"` // kid of the key that signs new tokens
+	Keys    []KeyConfig `json:"keys"`
+}
+
+// NewKeySet builds a key set that signs with the key called signing.
+func NewKeySet(signing string, keys ...Key) (*KeySet, error) {
+	ks := &KeySet{keys: make(map[string]Key), current: signing}
+	for _, k := range keys {
+		if k.ID == "" {
+			return nil, errors.New("every key needs a kid")
+		}
+		if _, ok := ks.keys[k.ID]; ok {
+			return nil, fmt.Errorf("duplicate kid %q", k.ID)
+		}
+		ks.keys[k.ID] = k
+	}
+	if k, ok := ks.keys[signing]; !ok || k.Signing == nil {
+		return nil, fmt.Errorf("signing key %q is missing or has no private key", signing)
+	}
+	return ks, nil
+}
+
+// LoadKeys reads signing keys from the environment. JWT_KEYS_FILE names a
+// JSON KeysConfig; JWT_SECRET is a shortcut for a single HS256 key. Without
+// either, a random key is generated, which logs everyone out on restart.
+func LoadKeys() (*KeySet, error) {
+	if path := os.Getenv("JWT_KEYS_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var cfg KeysConfig
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf("invalid keys file: %w", err)
+		}
+		return cfg.KeySet()
+	}
+
+	if secret := os.Getenv("JWT_SECRET"); secret != "" {
+		if len(secret) < minSecretLen {
+			return nil, fmt.Errorf("JWT_SECRET must be at least %d bytes", minSecretLen)
+		}
+		return NewKeySet("default", Key{ID: "default", Method: jwt.SigningMethodHS256, Signing: []byte(secret), Verify: []byte(secret)})
+	}
+
+	log.Println("JWT_SECRET and JWT_KEYS_FILE are unset, using a random signing key")
+	secret := make([]byte, minSecretLen)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	return NewKeySet("default", Key{ID: "default", Method: jwt.SigningMethodHS256, Signing: secret, Verify: secret})
+}
+
+func (cfg KeysConfig) KeySet() (*KeySet, error) {
+	var keys []Key
+	for _, kc := range cfg.Keys {
+		k, err := kc.key()
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", kc.ID, err)
+		}
+		keys = append(keys, k)
+	}
+	signing := cfg.Signing
+	if signing == "" && len(keys) > 0 {
+		signing = keys[0].ID
+	}
+	return NewKeySet(signing, keys...)
+}
+
+func (kc KeyConfig) key() (Key, error) {
+	k := Key{ID: kc.ID}
+	switch kc.Alg {
+	case "HS256":
+		if len(kc.Secret) < minSecretLen {
+			return k, fmt.Errorf("HS256 secrets must be at least %d bytes", minSecretLen)
+		}
+		k.Method = jwt.SigningMethodHS256
+		k.Signing, k.Verify = []byte(kc.Secret), []byte(kc.Secret)
+		return k, nil
+	case "RS256":
+		k.Method = jwt.SigningMethodRS256
+	case "EdDSA":
+		k.Method = jwt.SigningMethodEdDSA
+	default:
+		return k, fmt.Errorf("unsupported alg %q", kc.Alg)
+	}
+
+	if kc.PrivateKeyFile != "" {
+		pem, err := os.ReadFile(kc.PrivateKeyFile)
+		if err != nil {
+			return k, err
+		}
+		if kc.Alg == "RS256" {
+			priv, err := jwt.ParseRSAPrivateKeyFromPEM(pem)
+			if err != nil {
+				return k, err
+			}
+			k.Signing, k.Verify = priv, &priv.PublicKey
+		} else {
+			priv, err := jwt.ParseEdPrivateKeyFromPEM(pem)
+			if err != nil {
+				return k, err
+			}
+			k.Signing, k.Verify = priv, priv.(ed25519.PrivateKey).Public()
+		}
+		return k, nil
+	}
+
+	if kc.PublicKeyFile == "" {
+		return k, errors.New("privateKeyFile or publicKeyFile is required")
+	}
+	pem, err := os.ReadFile(kc.PublicKeyFile)
+	if err != nil {
+		return k, err
+	}
+	if kc.Alg == "RS256" {
+		k.Verify, err = jwt.ParseRSAPublicKeyFromPEM(pem)
+	} else {
+		k.Verify, err = jwt.ParseEdPublicKeyFromPEM(pem)
+	}
+	return k, err
+}
+
+// Sign signs claims with the current key, naming it in the kid header.
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	ks.mu.RLock()
+	k := ks.keys[ks.current]
+	ks.mu.RUnlock()
+
+	token := jwt.NewWithClaims(k.Method, claims)
+	token.Header["kid"] = k.ID
+	return token.SignedString(k.Signing)
+}
+
+// Keyfunc picks the verification key named by a token's kid. Tokens
+// without a kid were issued before rotation and are checked against the
+// current key. The token's alg must match the key's.
+func (ks *KeySet) Keyfunc(token *jwt.Token) (any, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		kid = ks.current
+	}
+	k, ok := ks.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown kid %q", kid)
+	}
+	if token.Method.Alg() != k.Method.Alg() {
+		return nil, errors.New("unexpected signing method")
+	}
+	return k.Verify, nil
+}
+
+// JWK is a public key in JSON Web Key format.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS lists the public keys. HMAC secrets are never published.
+func (ks *KeySet) JWKS() JWKS {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	set := JWKS{Keys: []JWK{}}
+	for _, k := range ks.keys {
+		jwk := JWK{Kid: k.ID, Alg: k.Method.Alg(), Use: "sig"}
+		switch pub := k.Verify.(type) {
+		case ed25519.PublicKey:
+			jwk.Kty, jwk.Crv, jwk.X = "OKP", "Ed25519", b64(pub)
+		case *rsa.PublicKey:
+			jwk.Kty, jwk.N, jwk.E = "RSA", b64(pub.N.Bytes()), b64(big.NewInt(int64(pub.E)).Bytes())
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return set
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -1,14 +1,19 @@
 package auth
 
 import (
+	"context"
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-// Middleware to protect routes
-func AuthMiddleware() gin.HandlerFunc {
+// Middleware to protect routes. It accepts access tokens, rejecting those
+// of revoked sessions right away rather than when they expire, and API
+// tokens, whose scopes RequireScope checks.
+func AuthMiddleware(tokens *Tokens) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -17,13 +22,110 @@ func AuthMiddleware() gin.HandlerFunc {
 		}
 
 		tokenStr := strings.TrimPrefix(authHeader, "Bearer ")
+		if strings.HasPrefix(tokenStr, APITokenPrefix) {
+			token, err := tokens.VerifyAPIToken(c.Request.Context(), tokenStr)
+			if err == ErrInvalidAPIToken {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+				return
+			}
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			c.Set("user_id", token.UserID.Hex())
+			c.Set("api_token", token)
+			c.Next()
+			return
+		}
+
 		claims, err := VerifyToken(tokenStr)
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
 			return
 		}
 
+		active, err := tokens.SessionActive(c.Request.Context(), claims.SessionID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if !active {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "session revoked"})
+			return
+		}
+
 		c.Set("user_id", claims.UserID)
+		c.Set("session_id", claims.SessionID)
+		c.Next()
+	}
+}
+
+// CurrentUser returns the user AuthMiddleware let through, answering
+// with an error if there is none.
+func CurrentUser(c *gin.Context) (primitive.ObjectID, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "user ID not found in context"})
+		return primitive.NilObjectID, false
+	}
+
+	userObjID, err := primitive.ObjectIDFromHex(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+		return primitive.NilObjectID, false
+	}
+	return userObjID, true
+}
+
+// QueryToken lets a route take its token from the access_token query
+// parameter, as browsers can't set headers on WebSocket and EventSource
+// requests. Only stream routes use it, so tokens don't end up in the
+// URLs of ordinary requests. It must run before AuthMiddleware.
+func QueryToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" && c.Query("access_token") != "" {
+			c.Request.Header.Set("Authorization", "Bearer "+c.Query("access_token"))
+		}
+		c.Next()
+	}
+}
+
+// RoleLookup returns the current roles of a user.
+type RoleLookup func(ctx context.Context, userID string) ([]string, error)
+
+// RequireRole only lets through users that currently have the role. It
+// must run after AuthMiddleware.
+func RequireRole(role string, lookup RoleLookup) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		roles, err := lookup(c.Request.Context(), c.GetString("user_id"))
+		if err != nil || !slices.Contains(roles, role) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireScope only lets API tokens through that have the scope. Logged
+// in users have every scope.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token, ok := c.Get("api_token"); ok && !token.(APIToken).HasScope(scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "API token lacks the " + scope + " scope"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// SessionOnly keeps API tokens out of routes that manage the account
+// itself, such as passwords, sessions and API tokens.
+func SessionOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get("api_token"); ok {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "not available to API tokens"})
+			return
+		}
 		c.Next()
 	}
 }
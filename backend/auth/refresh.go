@@ -0,0 +1,286 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	AccessTokenTTL  = 15 * time.Minute
+	RefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reused, please log in again")
+	ErrNoSuchSession       = errors.New("session not found")
+)
+
+// RefreshToken is stored by hash only. Every refresh replaces the token
+// with a new one in the same family; a family is one login.
+type RefreshToken struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	UserID    primitive.ObjectID `bson:"userId"`
+	Family    primitive.ObjectID `bson:"family"`
+	Hash      string             `bson:"hash"`
+	Used      bool               `bson:"used"`
+	Revoked   bool               `bson:"revoked"`
+	CreatedAt time.Time          `bson:"createdAt"`
+	ExpiresAt time.Time          `bson:"expiresAt"`
+}
+
+type TokenPair struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+	ExpiresIn    int    `json:"expiresIn"` // access token lifetime in seconds
+}
+
+type TokenRepository interface {
+	Add(ctx context.Context, t RefreshToken) error
+	FindByHash(ctx context.Context, hash string) (RefreshToken, error)
+	// MarkUsed flags a token as rotated, returning false if it already was.
+	MarkUsed(ctx context.Context, id primitive.ObjectID) (bool, error)
+	RevokeFamily(ctx context.Context, family primitive.ObjectID) error
+	RevokeUser(ctx context.Context, userID primitive.ObjectID) error
+	RemoveUser(ctx context.Context, userID primitive.ObjectID) error
+}
+
+type mongoTokenRepository struct {
+	col *mongo.Collection
+}
+
+func NewMongoTokenRepository(col *mongo.Collection) TokenRepository {
+	return &mongoTokenRepository{col: col}
+}
+
+func (r *mongoTokenRepository) Add(ctx context.Context, t RefreshToken) error {
+	_, err := r.col.InsertOne(ctx, t)
+	return err
+}
+
+func (r *mongoTokenRepository) FindByHash(ctx context.Context, hash string) (RefreshToken, error) {
+	var t RefreshToken
+	err := r.col.FindOne(ctx, bson.M{"hash": hash}).Decode(&t)
+	return t, err
+}
+
+func (r *mongoTokenRepository) MarkUsed(ctx context.Context, id primitive.ObjectID) (bool, error) {
+	result, err := r.col.UpdateOne(ctx, bson.M{"_id": id, "used": false}, bson.M{"$set": bson.M{"used": true}})
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+func (r *mongoTokenRepository) RevokeFamily(ctx context.Context, family primitive.ObjectID) error {
+	_, err := r.col.UpdateMany(ctx, bson.M{"family": family}, bson.M{"$set": bson.M{"revoked": true}})
+	return err
+}
+
+func (r *mongoTokenRepository) RevokeUser(ctx context.Context, userID primitive.ObjectID) error {
+	_, err := r.col.UpdateMany(ctx, bson.M{"userId": userID}, bson.M{"$set": bson.M{"revoked": true}})
+	return err
+}
+
+func (r *mongoTokenRepository) RemoveUser(ctx context.Context, userID primitive.ObjectID) error {
+	_, err := r.col.DeleteMany(ctx, bson.M{"userId": userID})
+	return err
+}
+
+// Tokens issues access tokens together with rotating refresh tokens, and
+// keeps track of the session each login starts. It also manages API
+// tokens.
+type Tokens struct {
+	repo      TokenRepository
+	sessions  SessionRepository
+	apiTokens APITokenRepository
+	now       func() time.Time
+}
+
+func NewTokens(repo TokenRepository, sessions SessionRepository, apiTokens APITokenRepository) *Tokens {
+	return &Tokens{repo: repo, sessions: sessions, apiTokens: apiTokens, now: time.Now}
+}
+
+// Issue starts a new session and token family for a fresh login.
+func (t *Tokens) Issue(ctx context.Context, userID primitive.ObjectID, client Client) (TokenPair, error) {
+	family := primitive.NewObjectID()
+	if err := t.addSession(ctx, family, userID, client); err != nil {
+		return TokenPair{}, err
+	}
+	return t.issue(ctx, userID, family)
+}
+
+// Refresh swaps a refresh token for a new pair. Presenting a token that was
+// already swapped means it leaked, so the whole family is revoked.
+func (t *Tokens) Refresh(ctx context.Context, raw string, client Client) (TokenPair, error) {
+	rt, err := t.repo.FindByHash(ctx, hashToken(raw))
+	if err == mongo.ErrNoDocuments {
+		return TokenPair{}, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return TokenPair{}, err
+	}
+	if rt.Revoked || !t.now().Before(rt.ExpiresAt) {
+		return TokenPair{}, ErrInvalidRefreshToken
+	}
+
+	fresh, err := t.repo.MarkUsed(ctx, rt.ID)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	if !fresh {
+		if err := t.revoke(ctx, rt.Family); err != nil {
+			return TokenPair{}, err
+		}
+		return TokenPair{}, ErrRefreshTokenReused
+	}
+
+	now := t.now()
+	live, err := t.sessions.Touch(ctx, rt.Family, client, now, now.Add(RefreshTokenTTL))
+	if err != nil {
+		return TokenPair{}, err
+	}
+	if !live {
+		// Families from before sessions were recorded get one now
+		if _, err := t.sessions.FindByID(ctx, rt.Family); err != mongo.ErrNoDocuments {
+			if err := t.revoke(ctx, rt.Family); err != nil {
+				return TokenPair{}, err
+			}
+			return TokenPair{}, ErrInvalidRefreshToken
+		}
+		if err := t.addSession(ctx, rt.Family, rt.UserID, client); err != nil {
+			return TokenPair{}, err
+		}
+	}
+	return t.issue(ctx, rt.UserID, rt.Family)
+}
+
+// Logout revokes the session a refresh token belongs to.
+func (t *Tokens) Logout(ctx context.Context, raw string) error {
+	rt, err := t.repo.FindByHash(ctx, hashToken(raw))
+	if err == mongo.ErrNoDocuments {
+		return ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return err
+	}
+	return t.revoke(ctx, rt.Family)
+}
+
+// LogoutAll revokes every session of a user.
+func (t *Tokens) LogoutAll(ctx context.Context, userID primitive.ObjectID) error {
+	if err := t.repo.RevokeUser(ctx, userID); err != nil {
+		return err
+	}
+	return t.sessions.RevokeUser(ctx, userID)
+}
+
+// Forget revokes every session and API token of a user and deletes their
+// records, refresh tokens included.
+func (t *Tokens) Forget(ctx context.Context, userID primitive.ObjectID) error {
+	if err := t.LogoutAll(ctx, userID); err != nil {
+		return err
+	}
+	if err := t.RevokeAPITokens(ctx, userID); err != nil {
+		return err
+	}
+	if err := t.sessions.RemoveUser(ctx, userID); err != nil {
+		return err
+	}
+	return t.repo.RemoveUser(ctx, userID)
+}
+
+// Sessions returns where a user is logged in.
+func (t *Tokens) Sessions(ctx context.Context, userID primitive.ObjectID) ([]Session, error) {
+	return t.sessions.FindByUser(ctx, userID, t.now())
+}
+
+// RevokeSession logs one of a user's sessions out.
+func (t *Tokens) RevokeSession(ctx context.Context, userID, sessionID primitive.ObjectID) error {
+	s, err := t.sessions.FindByID(ctx, sessionID)
+	if err == mongo.ErrNoDocuments || (err == nil && s.UserID != userID) {
+		return ErrNoSuchSession
+	}
+	if err != nil {
+		return err
+	}
+	return t.revoke(ctx, s.ID)
+}
+
+// SessionActive reports whether access tokens of a session are still
+// accepted.
+func (t *Tokens) SessionActive(ctx context.Context, sessionID string) (bool, error) {
+	id, err := primitive.ObjectIDFromHex(sessionID)
+	if err != nil {
+		return false, nil
+	}
+	s, err := t.sessions.FindByID(ctx, id)
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return !s.Revoked && t.now().Before(s.ExpiresAt), nil
+}
+
+func (t *Tokens) addSession(ctx context.Context, family, userID primitive.ObjectID, client Client) error {
+	now := t.now()
+	return t.sessions.Add(ctx, Session{
+		ID:         family,
+		UserID:     userID,
+		DeviceName: client.DeviceName,
+		UserAgent:  client.UserAgent,
+		IP:         client.IP,
+		CreatedAt:  now,
+		LastUsed:   now,
+		ExpiresAt:  now.Add(RefreshTokenTTL),
+	})
+}
+
+func (t *Tokens) revoke(ctx context.Context, family primitive.ObjectID) error {
+	if err := t.repo.RevokeFamily(ctx, family); err != nil {
+		return err
+	}
+	return t.sessions.Revoke(ctx, family)
+}
+
+func (t *Tokens) issue(ctx context.Context, userID, family primitive.ObjectID) (TokenPair, error) {
+	access, err := GenerateToken(userID.Hex(), family.Hex())
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return TokenPair{}, err
+	}
+	raw := base64.RawURLEncoding.EncodeToString(buf)
+
+	now := t.now()
+	err = t.repo.Add(ctx, RefreshToken{
+		UserID:    userID,
+		Family:    family,
+		Hash:      hashToken(raw),
+		CreatedAt: now,
+		ExpiresAt: now.Add(RefreshTokenTTL),
+	})
+	if err != nil {
+		return TokenPair{}, err
+	}
+	return TokenPair{AccessToken: access, RefreshToken: raw, ExpiresIn: int(AccessTokenTTL / time.Second)}, nil
+}
+
+func hashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const maxDeviceNameLength = 100
+
+// Session is one login, shared by every refresh token of a family and
+// every access token issued from them. Its ID is the family ID, which
+// access tokens carry as their sid claim.
+type Session struct {
+	ID         primitive.ObjectID `bson:"_id" json:"id"`
+	UserID     primitive.ObjectID `bson:"userId" json:"-"`
+	DeviceName string             `bson:"deviceName,omitempty" json:"deviceName,omitempty"`
+	UserAgent  string             `bson:"userAgent,omitempty" json:"userAgent,omitempty"`
+	IP         string             `bson:"ip,omitempty" json:"ip,omitempty"`
+	CreatedAt  time.Time          `bson:"createdAt" json:"createdAt"`
+	LastUsed   time.Time          `bson:"lastUsed" json:"lastUsed"` // as of the last token refresh
+	ExpiresAt  time.Time          `bson:"expiresAt" json:"expiresAt"`
+	Revoked    bool               `bson:"revoked,omitempty" json:"-"`
+}
+
+type SessionResponse struct {
+	Session
+	Current bool `json:"current"` // the session making the request
+}
+
+// Client describes where a session is used from.
+type Client struct {
+	DeviceName string
+	UserAgent  string
+	IP         string
+}
+
+// ClientFrom describes the client making a request. Apps name their
+// device in the X-Device-Name header.
+func ClientFrom(c *gin.Context) Client {
+	name := c.GetHeader("X-Device-Name")
+	if len(name) > maxDeviceNameLength {
+		name = name[:maxDeviceNameLength]
+	}
+	return Client{DeviceName: name, UserAgent: c.Request.UserAgent(), IP: c.ClientIP()}
+}
+
+type SessionRepository interface {
+	Add(ctx context.Context, s Session) error
+	// Touch records a refresh, returning false if the session is revoked.
+	Touch(ctx context.Context, id primitive.ObjectID, client Client, used, expires time.Time) (bool, error)
+	FindByID(ctx context.Context, id primitive.ObjectID) (Session, error)
+	FindByUser(ctx context.Context, userID primitive.ObjectID, now time.Time) ([]Session, error)
+	Revoke(ctx context.Context, id primitive.ObjectID) error
+	RevokeUser(ctx context.Context, userID primitive.ObjectID) error
+	RemoveUser(ctx context.Context, userID primitive.ObjectID) error
+}
+
+type mongoSessionRepository struct {
+	col *mongo.Collection
+}
+
+func NewMongoSessionRepository(col *mongo.Collection) SessionRepository {
+	return &mongoSessionRepository{col: col}
+}
+
+func (r *mongoSessionRepository) Add(ctx context.Context, s Session) error {
+	_, err := r.col.InsertOne(ctx, s)
+	return err
+}
+
+func (r *mongoSessionRepository) Touch(ctx context.Context, id primitive.ObjectID, client Client, used, expires time.Time) (bool, error) {
+	set := bson.M{"lastUsed": used, "expiresAt": expires, "ip": client.IP, "userAgent": client.UserAgent}
+	if client.DeviceName != "" {
+		set["deviceName"] = client.DeviceName
+	}
+	result, err := r.col.UpdateOne(ctx, bson.M{"_id": id, "revoked": bson.M{"$ne": true}}, bson.M{"$set": set})
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount == 1, nil
+}
+
+func (r *mongoSessionRepository) FindByID(ctx context.Context, id primitive.ObjectID) (Session, error) {
+	var s Session
+	err := r.col.FindOne(ctx, bson.M{"_id": id}).Decode(&s)
+	return s, err
+}
+
+// FindByUser returns the user's live sessions, most recently used first.
+func (r *mongoSessionRepository) FindByUser(ctx context.Context, userID primitive.ObjectID, now time.Time) ([]Session, error) {
+	filter := bson.M{"userId": userID, "revoked": bson.M{"$ne": true}, "expiresAt": bson.M{"$gt": now}}
+	cursor, err := r.col.Find(ctx, filter, options.Find().SetSort(bson.M{"lastUsed": -1}))
+	if err != nil {
+		return nil, err
+	}
+	sessions := []Session{}
+	if err := cursor.All(ctx, &sessions); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (r *mongoSessionRepository) Revoke(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.col.UpdateByID(ctx, id, bson.M{"$set": bson.M{"revoked": true}})
+	return err
+}
+
+func (r *mongoSessionRepository) RevokeUser(ctx context.Context, userID primitive.ObjectID) error {
+	_, err := r.col.UpdateMany(ctx, bson.M{"userId": userID}, bson.M{"$set": bson.M{"revoked": true}})
+	return err
+}
+
+func (r *mongoSessionRepository) RemoveUser(ctx context.Context, userID primitive.ObjectID) error {
+	_, err := r.col.DeleteMany(ctx, bson.M{"userId": userID})
+	return err
+}
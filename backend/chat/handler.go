@@ -0,0 +1,254 @@
+package chat
+
+import (
+	"irl-mafia-game/game"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// NoticeChat is the stream notice type for new and changed messages.
+const NoticeChat = "chat"
+
+const (
+	maxMessageLength = 1000
+	maxEmojiLength   = 32
+	defaultPageSize  = 50
+	maxPageSize      = 100
+)
+
+type SendMessageRequest struct {
+	Text string `json:"text" binding:"required"`
+}
+
+type ReactionRequest struct {
+	Emoji string `json:"emoji" binding:"required"`
+}
+
+// GetMessagesHandler godoc
+// @Summary List chat messages
+// @Description Page backwards through a game's chat, newest first. Pass the oldest ID seen as before for the next page.
+// @Tags chat
+// @Produce json
+// @Param id path string true "Game ID"
+// @Param before query string false "Only messages older than this message ID"
+// @Param limit query int false "Page size, at most 100"
+// @Success 200 {array} Message
+// @Router /games/{id}/chat [get]
+// @Security BearerAuth
+func GetMessagesHandler(repo MessageRepository, gameRepo game.GameRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_, g, ok := playerGame(c, gameRepo)
+		if !ok {
+			return
+		}
+
+		var before primitive.ObjectID
+		if b := c.Query("before"); b != "" {
+			var err error
+			if before, err = primitive.ObjectIDFromHex(b); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid message ID"})
+				return
+			}
+		}
+
+		limit := defaultPageSize
+		if l := c.Query("limit"); l != "" {
+			n, err := strconv.Atoi(l)
+			if err != nil || n < 1 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+				return
+			}
+			limit = min(n, maxPageSize)
+		}
+
+		messages, err := repo.FindByGame(c.Request.Context(), g.ID, before, int64(limit))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if messages == nil {
+			messages = []Message{}
+		}
+		c.JSON(http.StatusOK, messages)
+	}
+}
+
+// SendMessageHandler godoc
+// @Summary Send a chat message
+// @Description Post a message to a game's chat
+// @Tags chat
+// @Accept json
+// @Produce json
+// @Param id path string true "Game ID"
+// @Param message body SendMessageRequest true "Message"
+// @Success 200 {object} Message
+// @Router /games/{id}/chat [post]
+// @Security BearerAuth
+func SendMessageHandler(repo MessageRepository, gameRepo game.GameRepository, hub *game.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req SendMessageRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		text := strings.TrimSpace(req.Text)
+		if text == "" || len(text) > maxMessageLength {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "message must be 1 to 1000 characters"})
+			return
+		}
+
+		userObjID, g, ok := playerGame(c, gameRepo)
+		if !ok {
+			return
+		}
+
+		m := Message{GameID: g.ID, UserID: userObjID, Kind: KindUser, Text: text, CreatedAt: time.Now()}
+		id, err := repo.Add(c.Request.Context(), m)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		m.ID = id
+
+		hub.Notify(g.ID, game.Notice{Type: NoticeChat, Data: m})
+		c.JSON(http.StatusOK, m)
+	}
+}
+
+// ReactHandler godoc
+// @Summary React to a chat message
+// @Description Add an emoji reaction to a message, or remove it if already there
+// @Tags chat
+// @Accept json
+// @Produce json
+// @Param id path string true "Game ID"
+// @Param messageId path string true "Message ID"
+// @Param reaction body ReactionRequest true "Reaction"
+// @Success 200 {object} Message
+// @Router /games/{id}/chat/{messageId}/reactions [post]
+// @Security BearerAuth
+func ReactHandler(repo MessageRepository, gameRepo game.GameRepository, hub *game.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req ReactionRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if !validEmoji(req.Emoji) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid emoji"})
+			return
+		}
+
+		userObjID, g, ok := playerGame(c, gameRepo)
+		if !ok {
+			return
+		}
+		m, ok := gameMessage(c, repo, g)
+		if !ok {
+			return
+		}
+		if m.Deleted {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "message was deleted"})
+			return
+		}
+
+		m, err := repo.ToggleReaction(c.Request.Context(), m.ID, req.Emoji, userObjID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		hub.Notify(g.ID, game.Notice{Type: NoticeChat, Data: m})
+		c.JSON(http.StatusOK, m)
+	}
+}
+
+// DeleteMessageHandler godoc
+// @Summary Delete a chat message
+// @Description Let the host, or the author, remove a message
+// @Tags chat
+// @Produce json
+// @Param id path string true "Game ID"
+// @Param messageId path string true "Message ID"
+// @Success 200 {object} Message
+// @Router /games/{id}/chat/{messageId} [delete]
+// @Security BearerAuth
+func DeleteMessageHandler(repo MessageRepository, gameRepo game.GameRepository, hub *game.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userObjID, g, ok := playerGame(c, gameRepo)
+		if !ok {
+			return
+		}
+		m, ok := gameMessage(c, repo, g)
+		if !ok {
+			return
+		}
+		if userObjID != g.Host && (m.Kind != KindUser || userObjID != m.UserID) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "only the host or the author can delete a message"})
+			return
+		}
+
+		m, err := repo.Delete(c.Request.Context(), m.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		hub.Notify(g.ID, game.Notice{Type: NoticeChat, Data: m})
+		c.JSON(http.StatusOK, m)
+	}
+}
+
+// playerGame loads the game in the path and checks the current user plays
+// in it. On failure it writes the error response and returns false.
+func playerGame(c *gin.Context, gameRepo game.GameRepository) (primitive.ObjectID, game.Game, bool) {
+	userObjID, g, ok := game.LoadGame(c, gameRepo)
+	if !ok {
+		return primitive.NilObjectID, game.Game{}, false
+	}
+	if !slices.Contains(g.Players, userObjID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": game.ErrNotInGame.Error()})
+		return primitive.NilObjectID, game.Game{}, false
+	}
+	return userObjID, g, true
+}
+
+func gameMessage(c *gin.Context, repo MessageRepository, g game.Game) (Message, bool) {
+	messageID, err := primitive.ObjectIDFromHex(c.Param("messageId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid message ID"})
+		return Message{}, false
+	}
+	m, err := repo.FindByID(c.Request.Context(), messageID)
+	if err == mongo.ErrNoDocuments || (err == nil && m.GameID != g.ID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "message not found"})
+		return Message{}, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return Message{}, false
+	}
+	return m, true
+}
+
+// validEmoji accepts short strings without spaces that are safe to use as
+// a Mongo field name.
+func validEmoji(s string) bool {
+	if s == "" || len(s) > maxEmojiLength || strings.ContainsAny(s, ".$") {
+		return false
+	}
+	for _, r := range s {
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return false
+		}
+	}
+	return true
+}
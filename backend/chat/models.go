@@ -0,0 +1,24 @@
+package chat
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	KindUser   = "user"
+	KindSystem = "system" // public game events, written by the server
+)
+
+type Message struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	GameID    primitive.ObjectID  `bson:"gameId" json:"gameId"`
+	UserID    primitive.ObjectID  `bson:"userId,omitempty" json:"userId,omitempty"`
+	Kind      string              `bson:"kind" json:"kind"`
+	Text      string              `bson:"text" json:"text"`
+	EventSeq  int                 `bson:"eventSeq,omitempty" json:"eventSeq,omitempty"`   // system messages only
+	Reactions map[string][]string `bson:"reactions,omitempty" json:"reactions,omitempty"` // emoji to user IDs
+	Deleted   bool                `bson:"deleted,omitempty" json:"deleted,omitempty"`
+	CreatedAt time.Time           `bson:"createdAt" json:"createdAt"`
+}
@@ -0,0 +1,142 @@
+package chat
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MessageRepository interface {
+	Add(ctx context.Context, m Message) (primitive.ObjectID, error)
+	FindByID(ctx context.Context, id primitive.ObjectID) (Message, error)
+	FindByGame(ctx context.Context, gameID, before primitive.ObjectID, limit int64) ([]Message, error)
+	ToggleReaction(ctx context.Context, id primitive.ObjectID, emoji string, userID primitive.ObjectID) (Message, error)
+	Delete(ctx context.Context, id primitive.ObjectID) (Message, error)
+	FindByUser(ctx context.Context, userID primitive.ObjectID) ([]Message, error)
+	// RemoveUser deletes a user's messages and takes back their reactions
+	// in the given games.
+	RemoveUser(ctx context.Context, userID primitive.ObjectID, gameIDs []primitive.ObjectID) error
+}
+
+type mongoRepository struct {
+	col *mongo.Collection
+}
+
+func NewMongoRepository(col *mongo.Collection) MessageRepository {
+	return &mongoRepository{col: col}
+}
+
+func (r *mongoRepository) Add(ctx context.Context, m Message) (primitive.ObjectID, error) {
+	res, err := r.col.InsertOne(ctx, m)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	return res.InsertedID.(primitive.ObjectID), nil
+}
+
+func (r *mongoRepository) FindByID(ctx context.Context, id primitive.ObjectID) (Message, error) {
+	var m Message
+	err := r.col.FindOne(ctx, bson.M{"_id": id}).Decode(&m)
+	return m, err
+}
+
+// FindByGame pages backwards through a game's chat: the newest messages
+// older than before, newest first. A nil before starts from the latest.
+func (r *mongoRepository) FindByGame(ctx context.Context, gameID, before primitive.ObjectID, limit int64) ([]Message, error) {
+	filter := bson.M{"gameId": gameID}
+	if !before.IsZero() {
+		filter["_id"] = bson.M{"$lt": before}
+	}
+
+	var messages []Message
+	opts := options.Find().SetSort(bson.M{"_id": -1}).SetLimit(limit)
+	cursor, err := r.col.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(ctx, &messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// ToggleReaction adds the user's reaction, or takes it back if they had
+// already reacted with that emoji.
+func (r *mongoRepository) ToggleReaction(ctx context.Context, id primitive.ObjectID, emoji string, userID primitive.ObjectID) (Message, error) {
+	field := "reactions." + emoji
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var m Message
+	err := r.col.FindOneAndUpdate(ctx,
+		bson.M{"_id": id, field: userID.Hex()},
+		bson.M{"$pull": bson.M{field: userID.Hex()}},
+		opts,
+	).Decode(&m)
+	if err != mongo.ErrNoDocuments {
+		return m, err
+	}
+
+	err = r.col.FindOneAndUpdate(ctx,
+		bson.M{"_id": id},
+		bson.M{"$addToSet": bson.M{field: userID.Hex()}},
+		opts,
+	).Decode(&m)
+	return m, err
+}
+
+// Delete blanks a message but keeps its place in the history.
+func (r *mongoRepository) Delete(ctx context.Context, id primitive.ObjectID) (Message, error) {
+	var m Message
+	err := r.col.FindOneAndUpdate(ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{"deleted": true, "text": ""}, "$unset": bson.M{"reactions": ""}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&m)
+	return m, err
+}
+
+func (r *mongoRepository) FindByUser(ctx context.Context, userID primitive.ObjectID) ([]Message, error) {
+	var messages []Message
+	cursor, err := r.col.Find(ctx, bson.M{"userId": userID, "deleted": bson.M{"$ne": true}}, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(ctx, &messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+func (r *mongoRepository) RemoveUser(ctx context.Context, userID primitive.ObjectID, gameIDs []primitive.ObjectID) error {
+	_, err := r.col.UpdateMany(ctx,
+		bson.M{"userId": userID},
+		bson.M{"$set": bson.M{"deleted": true, "text": ""}, "$unset": bson.M{"reactions": ""}},
+	)
+	if err != nil {
+		return err
+	}
+	if len(gameIDs) == 0 {
+		return nil
+	}
+
+	// Reactions are keyed by emoji, so rebuild each map without the user
+	// and drop emojis nobody is left on
+	_, err = r.col.UpdateMany(ctx,
+		bson.M{"gameId": bson.M{"$in": gameIDs}, "reactions": bson.M{"$exists": true}},
+		mongo.Pipeline{{{Key: "$set", Value: bson.M{"reactions": bson.M{"$arrayToObject": bson.M{
+			"$filter": bson.M{
+				"input": bson.M{"$map": bson.M{
+					"input": bson.M{"$objectToArray": "$reactions"},
+					"as":    "r",
+					"in":    bson.M{"k": "$$r.k", "v": bson.M{"$setDifference": bson.A{"$$r.v", bson.A{userID.Hex()}}}},
+				}},
+				"as":   "r",
+				"cond": bson.M{"$gt": bson.A{bson.M{"$size": "$$r.v"}, 0}},
+			},
+		}}}}}},
+	)
+	return err
+}
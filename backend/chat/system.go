@@ -0,0 +1,83 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"irl-mafia-game/game"
+	"irl-mafia-game/user"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SystemListener posts a system message for every public game event. It
+// works from the public view of each event, so nothing hints at who has
+// Cooties.
+func SystemListener(repo MessageRepository, users user.UserRepository, hub *game.Hub) game.Listener {
+	return func(g game.Game, events []game.Event) {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			for _, ev := range events {
+				public, ok := ev.RedactedFor(primitive.NilObjectID)
+				if !ok {
+					continue
+				}
+				text := systemText(ctx, users, g, public)
+				if text == "" {
+					continue
+				}
+
+				m := Message{GameID: g.ID, Kind: KindSystem, Text: text, EventSeq: ev.Seq, CreatedAt: ev.Time}
+				id, err := repo.Add(ctx, m)
+				if err != nil {
+					log.Println("Failed to post system message:", err)
+					return
+				}
+				m.ID = id
+				hub.Notify(g.ID, game.Notice{Type: NoticeChat, Data: m})
+			}
+		}()
+	}
+}
+
+func systemText(ctx context.Context, users user.UserRepository, g game.Game, ev game.Event) string {
+	name := func(id primitive.ObjectID) string { return playerName(ctx, users, g, id) }
+	switch ev.Type {
+	case game.EventClaim:
+		return fmt.Sprintf("%s claimed %s.", name(ev.Actor), name(ev.Target))
+	case game.EventGuess:
+		return fmt.Sprintf("%s made a guess about %s.", name(ev.Actor), name(ev.Target))
+	case game.EventWin:
+		return fmt.Sprintf("%s got bingo with %s!", name(ev.Actor), ev.Pattern)
+	case game.EventGameFinished:
+		switch {
+		case ev.Actor.IsZero() && ev.Forced:
+			return "An admin ended the game early with no winner."
+		case ev.Actor.IsZero():
+			return "Game over! Nobody scored any points."
+		case ev.Forced:
+			return fmt.Sprintf("An admin ended the game early. %s wins.", name(ev.Actor))
+		}
+		return fmt.Sprintf("Game over! %s wins on points.", name(ev.Actor))
+	case game.EventDisputeOpened:
+		return fmt.Sprintf("%s disputed a claim by %s.", name(ev.Actor), name(ev.Target))
+	case game.EventDisputeResolved:
+		return fmt.Sprintf("The dispute over %s's claim was resolved.", name(ev.Target))
+	}
+	return ""
+}
+
+func playerName(ctx context.Context, users user.UserRepository, g game.Game, id primitive.ObjectID) string {
+	for _, p := range g.PlayerStates {
+		if p.User == id && (p.Bot || p.Deleted) {
+			return p.PlayerName
+		}
+	}
+	if u, err := users.FindUserWithID(ctx, id); err == nil {
+		return u.Username
+	}
+	return "Someone"
+}
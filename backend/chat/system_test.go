@@ -0,0 +1,47 @@
+package chat
+
+import (
+	"context"
+	"irl-mafia-game/game"
+	"irl-mafia-game/user"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type namedUsers struct {
+	user.UserRepository
+	names map[primitive.ObjectID]string
+}
+
+func (u namedUsers) FindUserWithID(ctx context.Context, id primitive.ObjectID) (user.User, error) {
+	if name, ok := u.names[id]; ok {
+		return user.User{ID: id, Username: name}, nil
+	}
+	return user.User{}, mongo.ErrNoDocuments
+}
+
+func TestSystemTextGameFinished(t *testing.T) {
+	alice := primitive.NewObjectID()
+	users := namedUsers{names: map[primitive.ObjectID]string{alice: "alice"}}
+	g := game.Game{ID: primitive.NewObjectID(), Players: []primitive.ObjectID{alice}}
+
+	tests := []struct {
+		name  string
+		event game.Event
+		want  string
+	}{
+		{"on points", game.Event{Type: game.EventGameFinished, Actor: alice}, "Game over! alice wins on points."},
+		{"no winner", game.Event{Type: game.EventGameFinished}, "Game over! Nobody scored any points."},
+		{"forced", game.Event{Type: game.EventGameFinished, Actor: alice, Forced: true}, "An admin ended the game early. alice wins."},
+		{"forced with no winner", game.Event{Type: game.EventGameFinished, Forced: true}, "An admin ended the game early with no winner."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := systemText(context.Background(), users, g, tt.event); got != tt.want {
+				t.Errorf("text = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,283 @@
+package chatbot
+
+import (
+	"context"
+	"fmt"
+	"irl-mafia-game/game"
+	"irl-mafia-game/user"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const helpText = `Commands:
+/link CODE - link your chat account (get a code in the app)
+/game GAME_ID - host only, play that game in this chat
+/claim @name - claim a player you met today
+/guess @name - guess who has Cooties
+/item ITEM [@name] - use mask, shield, thermometer or double_claim
+/board - show your board
+Moves and boards are answered privately, so start a chat with the bot first.`
+
+// Bot runs chat commands through the same engine and repositories as the
+// HTTP API.
+type Bot struct {
+	links  LinkRepository
+	users  user.UserRepository
+	games  game.GameRepository
+	engine *game.Engine
+}
+
+func NewBot(links LinkRepository, users user.UserRepository, games game.GameRepository, engine *game.Engine) *Bot {
+	return &Bot{links: links, users: users, games: games, engine: engine}
+}
+
+// Handle runs one chat message and returns the reply. Anything that isn't
+// a command gets an empty reply.
+func (b *Bot) Handle(ctx context.Context, in Inbound) Reply {
+	cmd, args := parseCommand(in.Text)
+	if cmd == "" {
+		return Reply{}
+	}
+
+	switch cmd {
+	case "help", "start":
+		return Reply{Text: helpText}
+	case "link":
+		return Reply{Text: b.link(ctx, in, args)}
+	}
+
+	l, err := b.links.FindLink(ctx, in.Platform, in.ChatUserID)
+	if err != nil {
+		return Reply{Text: "Link your account first: get a code in the app and send /link CODE."}
+	}
+	// Links outlive sessions, so check the account like RoleLookup does
+	if u, err := b.users.FindUserWithID(ctx, l.UserID); err != nil || u.Disabled {
+		return Reply{Text: "Your account can't play right now."}
+	}
+
+	switch cmd {
+	case "game":
+		return Reply{Text: b.bindGame(ctx, in, l.UserID, args)}
+	case "claim", "guess":
+		return b.act(ctx, in, l.UserID, game.ActionRequest{Action: cmd}, args)
+	case "item":
+		if len(args) == 0 {
+			return Reply{Text: "Which item? Try /item mask."}
+		}
+		return b.act(ctx, in, l.UserID, game.ActionRequest{Action: game.ActionItem, Item: args[0]}, args[1:])
+	case "board":
+		return b.board(ctx, in, l.UserID)
+	}
+	return Reply{Text: "Unknown command. Send /help for the list."}
+}
+
+func (b *Bot) link(ctx context.Context, in Inbound, args []string) string {
+	if len(args) != 1 {
+		return "Send /link CODE with the code from the app."
+	}
+	code, err := b.links.TakeCode(ctx, strings.ToUpper(args[0]))
+	if err != nil || time.Now().After(code.ExpiresAt) {
+		return "That code is invalid or expired."
+	}
+
+	l := Link{Platform: in.Platform, ChatUserID: in.ChatUserID, UserID: code.UserID, CreatedAt: time.Now()}
+	if err := b.links.Link(ctx, l); err != nil {
+		return "Couldn't link your account, try again."
+	}
+	u, err := b.users.FindUserWithID(ctx, code.UserID)
+	if err != nil {
+		return "Linked."
+	}
+	return "Linked to " + u.Username + "."
+}
+
+func (b *Bot) bindGame(ctx context.Context, in Inbound, userID primitive.ObjectID, args []string) string {
+	if len(args) != 1 {
+		return "Send /game GAME_ID."
+	}
+	gameID, err := primitive.ObjectIDFromHex(args[0])
+	if err != nil {
+		return "That isn't a game ID."
+	}
+	g, err := b.games.GetByID(ctx, gameID)
+	if err != nil {
+		return "Game not found."
+	}
+	if g.Host != userID {
+		return "Only the host can pick the game for this chat."
+	}
+	if err := b.links.BindChannel(ctx, Channel{Platform: in.Platform, ChannelID: in.ChannelID, GameID: gameID}); err != nil {
+		return "Couldn't save the game for this chat, try again."
+	}
+	return "This chat now plays game " + args[0] + "."
+}
+
+// act plays a move in the chat's game. The outcome is private: results,
+// items and even refusals can say who has Cooties.
+func (b *Bot) act(ctx context.Context, in Inbound, userID primitive.ObjectID, req game.ActionRequest, args []string) Reply {
+	g, reply := b.channelGame(ctx, in)
+	if reply != "" {
+		return Reply{Text: reply}
+	}
+
+	if len(args) > 0 {
+		target, err := b.resolvePlayer(ctx, g, args[0])
+		if err != nil {
+			return Reply{Text: err.Error()}
+		}
+		req.TargetID = target.Hex()
+	}
+
+	var result game.ActionResult
+	var applyErr error
+	err := game.Save(ctx, b.games, &g, func(g *game.Game) bool {
+		result, applyErr = b.engine.Apply(g, userID, req)
+		return applyErr == nil
+	})
+	if applyErr != nil {
+		return Reply{Text: "Can't do that: " + applyErr.Error() + ".", Private: true}
+	}
+	if err != nil {
+		return Reply{Text: "Couldn't save your move, try again.", Private: true}
+	}
+	return Reply{Text: describe(result), Private: true}
+}
+
+func (b *Bot) board(ctx context.Context, in Inbound, userID primitive.ObjectID) Reply {
+	g, reply := b.channelGame(ctx, in)
+	if reply != "" {
+		return Reply{Text: reply}
+	}
+
+	var me *game.Player
+	for i := range g.PlayerStates {
+		if g.PlayerStates[i].User == userID {
+			me = &g.PlayerStates[i]
+		}
+	}
+	if me == nil {
+		return Reply{Text: "You aren't in this game."}
+	}
+
+	names := make(map[primitive.ObjectID]string)
+	var sb strings.Builder
+	for i, t := range me.Board {
+		name, ok := names[t.FriendID]
+		if !ok {
+			name = b.playerName(ctx, g, t.FriendID)
+			names[t.FriendID] = name
+		}
+		switch {
+		case t.Free:
+			name = "FREE"
+		case t.Frozen:
+			name += " (?)"
+		case t.Claimed:
+			name += " ✓"
+		}
+		sb.WriteString(fmt.Sprintf("%-14s", name))
+		if (i+1)%g.BoardSize == 0 {
+			sb.WriteString("\n")
+		}
+	}
+	if len(me.Items) > 0 {
+		sb.WriteString("Items: " + strings.Join(me.Items, ", ") + "\n")
+	}
+	return Reply{Text: strings.TrimRight(sb.String(), "\n"), Private: true}
+}
+
+func (b *Bot) channelGame(ctx context.Context, in Inbound) (game.Game, string) {
+	ch, err := b.links.FindChannel(ctx, in.Platform, in.ChannelID)
+	if err != nil {
+		return game.Game{}, "This chat isn't playing a game yet. The host can send /game GAME_ID."
+	}
+	g, err := b.games.GetByID(ctx, ch.GameID)
+	if err != nil {
+		return game.Game{}, "Game not found."
+	}
+	return g, ""
+}
+
+// resolvePlayer finds a player by username, or by name for bots.
+func (b *Bot) resolvePlayer(ctx context.Context, g game.Game, name string) (primitive.ObjectID, error) {
+	name = strings.TrimPrefix(name, "@")
+	for _, p := range g.PlayerStates {
+		if p.Bot && strings.EqualFold(p.PlayerName, name) {
+			return p.User, nil
+		}
+	}
+	u, err := b.users.FindUserWithUsername(ctx, name)
+	if err != nil {
+		return primitive.NilObjectID, fmt.Errorf("no player called %s", name)
+	}
+	for _, id := range g.Players {
+		if id == u.ID {
+			return u.ID, nil
+		}
+	}
+	return primitive.NilObjectID, fmt.Errorf("%s isn't in this game", name)
+}
+
+func (b *Bot) playerName(ctx context.Context, g game.Game, id primitive.ObjectID) string {
+	if id.IsZero() {
+		return "-"
+	}
+	for _, p := range g.PlayerStates {
+		if p.User == id && (p.Bot || p.Deleted) {
+			return p.PlayerName
+		}
+	}
+	if u, err := b.users.FindUserWithID(ctx, id); err == nil {
+		return u.Username
+	}
+	return "?"
+}
+
+func describe(r game.ActionResult) string {
+	var parts []string
+	switch {
+	case r.Action == game.ActionGuess && r.Correct:
+		parts = append(parts, "Correct guess!")
+	case r.Action == game.ActionGuess:
+		parts = append(parts, "Wrong guess.")
+	case r.Item != "":
+		parts = append(parts, "Used "+r.Item+".")
+	default:
+		parts = append(parts, "Claimed.")
+	}
+	if r.HasCooties != nil {
+		if *r.HasCooties {
+			parts = append(parts, "They have Cooties.")
+		} else {
+			parts = append(parts, "They don't have Cooties.")
+		}
+	}
+	if len(r.Gained) > 0 {
+		parts = append(parts, fmt.Sprintf("Gained %d tile(s).", len(r.Gained)))
+	}
+	if len(r.Lost) > 0 {
+		parts = append(parts, fmt.Sprintf("Lost %d tile(s).", len(r.Lost)))
+	}
+	if len(r.Earned) > 0 {
+		parts = append(parts, "Earned "+strings.Join(r.Earned, ", ")+".")
+	}
+	if r.Won {
+		parts = append(parts, "BINGO ("+string(r.WinPattern)+")!")
+	}
+	return strings.Join(parts, " ")
+}
+
+// parseCommand splits "/claim@SomeBot @alex" into "claim" and ["@alex"].
+func parseCommand(text string) (string, []string) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+		return "", nil
+	}
+	cmd := strings.TrimPrefix(fields[0], "/")
+	if i := strings.Index(cmd, "@"); i >= 0 {
+		cmd = cmd[:i]
+	}
+	return strings.ToLower(cmd), fields[1:]
+}
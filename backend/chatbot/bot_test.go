@@ -0,0 +1,213 @@
+package chatbot
+
+import (
+	"context"
+	"irl-mafia-game/game"
+	"irl-mafia-game/user"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		text string
+		cmd  string
+		args []string
+	}{
+		{"/claim @alex", "claim", []string{"@alex"}},
+		{"/claim@MafiaBot @alex", "claim", []string{"@alex"}},
+		{"  /BOARD  ", "board", []string{}},
+		{"/item mask @alex", "item", []string{"mask", "@alex"}},
+		{"/", "", []string{}},
+		{"claim @alex", "", nil},
+		{"hello /claim", "", nil},
+		{"", "", nil},
+	}
+	for _, tt := range tests {
+		cmd, args := parseCommand(tt.text)
+		if cmd != tt.cmd || !slices.Equal(args, tt.args) {
+			t.Errorf("parseCommand(%q) = %q, %q, want %q, %q", tt.text, cmd, args, tt.cmd, tt.args)
+		}
+	}
+}
+
+type memLinks struct {
+	codes    map[string]LinkCode
+	links    []Link
+	channels []Channel
+}
+
+func (m *memLinks) AddCode(ctx context.Context, code LinkCode) error {
+	m.codes[code.Code] = code
+	return nil
+}
+
+func (m *memLinks) TakeCode(ctx context.Context, code string) (LinkCode, error) {
+	c, ok := m.codes[code]
+	if !ok {
+		return LinkCode{}, mongo.ErrNoDocuments
+	}
+	delete(m.codes, code)
+	return c, nil
+}
+
+func (m *memLinks) Link(ctx context.Context, l Link) error {
+	m.links = append(m.links, l)
+	return nil
+}
+
+func (m *memLinks) FindLink(ctx context.Context, platform, chatUserID string) (Link, error) {
+	for _, l := range m.links {
+		if l.Platform == platform && l.ChatUserID == chatUserID {
+			return l, nil
+		}
+	}
+	return Link{}, mongo.ErrNoDocuments
+}
+
+func (m *memLinks) FindLinksByUser(ctx context.Context, userID primitive.ObjectID) ([]Link, error) {
+	return nil, nil
+}
+
+func (m *memLinks) Unlink(ctx context.Context, userID, id primitive.ObjectID) error { return nil }
+
+func (m *memLinks) UnlinkUser(ctx context.Context, userID primitive.ObjectID) error { return nil }
+
+func (m *memLinks) BindChannel(ctx context.Context, ch Channel) error {
+	m.channels = append(m.channels, ch)
+	return nil
+}
+
+func (m *memLinks) FindChannel(ctx context.Context, platform, channelID string) (Channel, error) {
+	for _, ch := range m.channels {
+		if ch.Platform == platform && ch.ChannelID == channelID {
+			return ch, nil
+		}
+	}
+	return Channel{}, mongo.ErrNoDocuments
+}
+
+type memUsers struct {
+	user.UserRepository
+	users []user.User
+}
+
+func (m *memUsers) FindUserWithID(ctx context.Context, id primitive.ObjectID) (user.User, error) {
+	for _, u := range m.users {
+		if u.ID == id {
+			return u, nil
+		}
+	}
+	return user.User{}, mongo.ErrNoDocuments
+}
+
+func (m *memUsers) FindUserWithUsername(ctx context.Context, username string) (user.User, error) {
+	for _, u := range m.users {
+		if u.Username == username {
+			return u, nil
+		}
+	}
+	return user.User{}, mongo.ErrNoDocuments
+}
+
+type memGames struct {
+	game.GameRepository
+	game game.Game
+}
+
+func (m *memGames) GetByID(ctx context.Context, id primitive.ObjectID) (game.Game, error) {
+	if id != m.game.ID {
+		return game.Game{}, mongo.ErrNoDocuments
+	}
+	return m.game, nil
+}
+
+func (m *memGames) Update(ctx context.Context, g game.Game) error {
+	if g.Version != m.game.Version {
+		return game.ErrConflict
+	}
+	g.Version++
+	m.game = g
+	return nil
+}
+
+func TestBotHandle(t *testing.T) {
+	alice := user.User{ID: primitive.NewObjectID(), Username: "alice"}
+	bob := user.User{ID: primitive.NewObjectID(), Username: "bob"}
+	carol := user.User{ID: primitive.NewObjectID(), Username: "carol"}
+	erin := user.User{ID: primitive.NewObjectID(), Username: "erin", Disabled: true}
+
+	engine := game.NewEngine(rand.New(rand.NewSource(1)))
+	g := game.Game{
+		ID:        primitive.NewObjectID(),
+		Host:      alice.ID,
+		Players:   []primitive.ObjectID{alice.ID, bob.ID},
+		BoardSize: 3,
+		Status:    "active",
+		CreatedAt: time.Now(),
+		Rules:     game.Rules{Mode: game.ModeBingo},
+	}
+	engine.Deal(&g)
+	gameID := g.ID.Hex()
+
+	links := &memLinks{codes: map[string]LinkCode{
+		"ALICE1": {Code: "ALICE1", UserID: alice.ID, ExpiresAt: time.Now().Add(time.Minute)},
+		"BOB123": {Code: "BOB123", UserID: bob.ID, ExpiresAt: time.Now().Add(time.Minute)},
+		"OLD123": {Code: "OLD123", UserID: carol.ID, ExpiresAt: time.Now().Add(-time.Minute)},
+		"ERIN12": {Code: "ERIN12", UserID: erin.ID, ExpiresAt: time.Now().Add(time.Minute)},
+	}}
+	games := &memGames{game: g}
+	bot := NewBot(links, &memUsers{users: []user.User{alice, bob, carol, erin}}, games, engine)
+
+	// One conversation in a group chat, in order
+	steps := []struct {
+		from    string // chat user ID
+		text    string
+		want    string // prefix of the reply
+		private bool
+	}{
+		{"a", "good morning", "", false},
+		{"a", "/help", "Commands:", false},
+		{"a", "/board", "Link your account first", false},
+		{"a", "/link nope", "That code is invalid or expired.", false},
+		{"c", "/link OLD123", "That code is invalid or expired.", false},
+		{"a", "/link alice1", "Linked to alice.", false},
+		{"a", "/link alice1", "That code is invalid or expired.", false},
+		{"a", "/claim @bob", "This chat isn't playing a game yet.", false},
+		{"b", "/link BOB123", "Linked to bob.", false},
+		{"b", "/game " + gameID, "Only the host can pick the game for this chat.", false},
+		{"a", "/game nope", "That isn't a game ID.", false},
+		{"a", "/game " + gameID, "This chat now plays game " + gameID + ".", false},
+		{"a", "/claim @carol", "carol isn't in this game", false},
+		{"a", "/claim @dave", "no player called dave", false},
+		{"a", "/item", "Which item? Try /item mask.", false},
+		{"a", "/claim@MafiaBot @bob", "Claimed.", true},
+		{"a", "/claim @bob", "Can't do that: already acted today.", true},
+		{"b", "/item thermometer @alice", "Can't do that: item not in inventory.", true},
+		{"b", "/guess @alice", "Wrong guess.", true},
+		{"b", "/board", "alice", true},
+		{"b", "/frobnicate", "Unknown command.", false},
+		{"e", "/link ERIN12", "Linked to erin.", false},
+		{"e", "/board", "Your account can't play right now.", false},
+	}
+	for _, step := range steps {
+		reply := bot.Handle(context.Background(), Inbound{Platform: "local", ChatUserID: step.from, ChannelID: "group", Text: step.text})
+		if !strings.HasPrefix(reply.Text, step.want) || (step.want == "" && reply.Text != "") {
+			t.Fatalf("%s: %q replied %q, want %q", step.from, step.text, reply.Text, step.want)
+		}
+		if reply.Private != step.private {
+			t.Errorf("%s: %q replied with private %v, want %v", step.from, step.text, reply.Private, step.private)
+		}
+	}
+
+	saved := games.game
+	if saved.Version != 2 || len(saved.Events) != 2 || saved.Events[0].Actor != alice.ID || saved.Events[1].Type != game.EventGuess {
+		t.Errorf("saved game version %d with events %+v, want alice's claim and bob's guess saved once each", saved.Version, saved.Events)
+	}
+}
@@ -0,0 +1,212 @@
+package chatbot
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"irl-mafia-game/auth"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	PlatformLocal    = "local"
+	PlatformTelegram = "telegram"
+
+	linkCodeTTL      = 10 * time.Minute
+	linkCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789" // no lookalikes
+)
+
+type telegramUpdate struct {
+	Message *struct {
+		From struct {
+			ID int64 `json:"id"`
+		} `json:"from"`
+		Chat struct {
+			ID int64 `json:"id"`
+		} `json:"chat"`
+		Text string `json:"text"`
+	} `json:"message"`
+}
+
+// LocalWebhookHandler godoc
+// @Summary Send a chat command (local format)
+// @Description Run a slash command from the stand-in chat format, authenticated with the X-Chatbot-Token header
+// @Tags chat
+// @Accept json
+// @Produce json
+// @Param message body LocalMessage true "Chat message"
+// @Success 200 {object} LocalReply
+// @Router /chat/local [post]
+func LocalWebhookHandler(bot *Bot, secret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !validSecret(c.GetHeader("X-Chatbot-Token"), secret) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid chatbot token"})
+			return
+		}
+
+		var msg LocalMessage
+		if err := c.ShouldBindJSON(&msg); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		reply := bot.Handle(c.Request.Context(), Inbound{
+			Platform:   PlatformLocal,
+			ChatUserID: msg.ChatUserID,
+			ChannelID:  msg.ChannelID,
+			Text:       msg.Text,
+		})
+		c.JSON(http.StatusOK, LocalReply{Text: reply.Text, Private: reply.Private})
+	}
+}
+
+// TelegramWebhookHandler godoc
+// @Summary Receive a Telegram update
+// @Description Run a slash command from a Telegram bot webhook and answer with a sendMessage call. Moves and boards are answered in the sender's private chat with the bot
+// @Tags chat
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /chat/telegram [post]
+func TelegramWebhookHandler(bot *Bot, secret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !validSecret(c.GetHeader("X-Telegram-Bot-Api-Secret-Token"), secret) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid secret token"})
+			return
+		}
+
+		var update telegramUpdate
+		if err := c.ShouldBindJSON(&update); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if update.Message == nil {
+			c.Status(http.StatusOK)
+			return
+		}
+
+		chatID := update.Message.Chat.ID
+		reply := bot.Handle(c.Request.Context(), Inbound{
+			Platform:   PlatformTelegram,
+			ChatUserID: strconv.FormatInt(update.Message.From.ID, 10),
+			ChannelID:  strconv.FormatInt(chatID, 10),
+			Text:       update.Message.Text,
+		})
+		if reply.Text == "" {
+			c.Status(http.StatusOK)
+			return
+		}
+		// A user's ID is also the ID of their private chat with the bot
+		if reply.Private {
+			chatID = update.Message.From.ID
+		}
+
+		// Telegram runs a method returned in the webhook response
+		c.JSON(http.StatusOK, gin.H{"method": "sendMessage", "chat_id": chatID, "text": reply.Text})
+	}
+}
+
+// CreateLinkCodeHandler godoc
+// @Summary Get a chat link code
+// @Description Create a single-use code to send to the chat bot with /link
+// @Tags chat
+// @Produce json
+// @Success 200 {object} LinkCode
+// @Router /users/me/chat-links/code [post]
+// @Security BearerAuth
+func CreateLinkCodeHandler(repo LinkRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userObjID, ok := auth.CurrentUser(c)
+		if !ok {
+			return
+		}
+
+		raw := make([]byte, 8)
+		if _, err := rand.Read(raw); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate code"})
+			return
+		}
+		code := make([]byte, len(raw))
+		for i, b := range raw {
+			code[i] = linkCodeAlphabet[int(b)%len(linkCodeAlphabet)]
+		}
+
+		linkCode := LinkCode{Code: string(code), UserID: userObjID, ExpiresAt: time.Now().Add(linkCodeTTL)}
+		if err := repo.AddCode(c.Request.Context(), linkCode); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, linkCode)
+	}
+}
+
+// GetLinksHandler godoc
+// @Summary List chat links
+// @Description List the chat accounts linked to the current user
+// @Tags chat
+// @Produce json
+// @Success 200 {array} Link
+// @Router /users/me/chat-links [get]
+// @Security BearerAuth
+func GetLinksHandler(repo LinkRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userObjID, ok := auth.CurrentUser(c)
+		if !ok {
+			return
+		}
+
+		links, err := repo.FindLinksByUser(c.Request.Context(), userObjID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if links == nil {
+			links = []Link{}
+		}
+		c.JSON(http.StatusOK, links)
+	}
+}
+
+// DeleteLinkHandler godoc
+// @Summary Unlink a chat account
+// @Description Remove a chat account link of the current user
+// @Tags chat
+// @Produce json
+// @Param linkId path string true "Link ID"
+// @Success 200 {object} map[string]string
+// @Router /users/me/chat-links/{linkId} [delete]
+// @Security BearerAuth
+func DeleteLinkHandler(repo LinkRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userObjID, ok := auth.CurrentUser(c)
+		if !ok {
+			return
+		}
+
+		linkID, err := primitive.ObjectIDFromHex(c.Param("linkId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid link ID"})
+			return
+		}
+
+		err = repo.Unlink(c.Request.Context(), userObjID, linkID)
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "link not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "unlinked"})
+	}
+}
+
+func validSecret(got, want string) bool {
+	return want != "" && subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+}
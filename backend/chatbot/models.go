@@ -0,0 +1,60 @@
+package chatbot
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Link ties an account on a chat platform to a user.
+type Link struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Platform   string             `bson:"platform" json:"platform"`
+	ChatUserID string             `bson:"chatUserId" json:"chatUserId"`
+	UserID     primitive.ObjectID `bson:"userId" json:"userId"`
+	CreatedAt  time.Time          `bson:"createdAt" json:"createdAt"`
+}
+
+// LinkCode is a short-lived code a user sends to the chat bot with /link to
+// prove which account they are.
+type LinkCode struct {
+	Code      string             `bson:"_id" json:"code"`
+	UserID    primitive.ObjectID `bson:"userId" json:"-"`
+	ExpiresAt time.Time          `bson:"expiresAt" json:"expiresAt"`
+}
+
+// Channel ties a group chat to the game its commands act on.
+type Channel struct {
+	Platform  string             `bson:"platform"`
+	ChannelID string             `bson:"channelId"`
+	GameID    primitive.ObjectID `bson:"gameId"`
+}
+
+// Inbound is a chat message after an adapter has parsed it.
+type Inbound struct {
+	Platform   string
+	ChatUserID string
+	ChannelID  string
+	Text       string
+}
+
+// Reply is the bot's answer to a message. A private reply may carry
+// secrets, like a guess result or the sender's board, and must only reach
+// the sender.
+type Reply struct {
+	Text    string
+	Private bool
+}
+
+// LocalMessage is the stand-in payload for testing without a chat service.
+type LocalMessage struct {
+	ChatUserID string `json:"chatUserId" binding:"required"`
+	ChannelID  string `json:"channelId" binding:"required"`
+	Text       string `json:"text" binding:"required"`
+}
+
+// LocalReply is the response to a LocalMessage.
+type LocalReply struct {
+	Text    string `json:"text"`
+	Private bool   `json:"private,omitempty"` // show it to the sender only
+}
@@ -0,0 +1,105 @@
+package chatbot
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type LinkRepository interface {
+	AddCode(ctx context.Context, code LinkCode) error
+	TakeCode(ctx context.Context, code string) (LinkCode, error)
+	Link(ctx context.Context, l Link) error
+	FindLink(ctx context.Context, platform, chatUserID string) (Link, error)
+	FindLinksByUser(ctx context.Context, userID primitive.ObjectID) ([]Link, error)
+	Unlink(ctx context.Context, userID, id primitive.ObjectID) error
+	UnlinkUser(ctx context.Context, userID primitive.ObjectID) error
+	BindChannel(ctx context.Context, ch Channel) error
+	FindChannel(ctx context.Context, platform, channelID string) (Channel, error)
+}
+
+type mongoRepository struct {
+	links    *mongo.Collection
+	codes    *mongo.Collection
+	channels *mongo.Collection
+}
+
+func NewMongoRepository(links, codes, channels *mongo.Collection) LinkRepository {
+	return &mongoRepository{links: links, codes: codes, channels: channels}
+}
+
+func (r *mongoRepository) AddCode(ctx context.Context, code LinkCode) error {
+	_, err := r.codes.InsertOne(ctx, code)
+	return err
+}
+
+// TakeCode removes and returns a code so it can only be used once.
+func (r *mongoRepository) TakeCode(ctx context.Context, code string) (LinkCode, error) {
+	var c LinkCode
+	err := r.codes.FindOneAndDelete(ctx, bson.M{"_id": code}).Decode(&c)
+	return c, err
+}
+
+// Link ties a chat account to a user, replacing any earlier link for it.
+func (r *mongoRepository) Link(ctx context.Context, l Link) error {
+	_, err := r.links.UpdateOne(
+		ctx,
+		bson.M{"platform": l.Platform, "chatUserId": l.ChatUserID},
+		bson.M{"$set": bson.M{"userId": l.UserID, "createdAt": l.CreatedAt}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+func (r *mongoRepository) FindLink(ctx context.Context, platform, chatUserID string) (Link, error) {
+	var l Link
+	err := r.links.FindOne(ctx, bson.M{"platform": platform, "chatUserId": chatUserID}).Decode(&l)
+	return l, err
+}
+
+func (r *mongoRepository) FindLinksByUser(ctx context.Context, userID primitive.ObjectID) ([]Link, error) {
+	var links []Link
+	cursor, err := r.links.Find(ctx, bson.M{"userId": userID})
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(ctx, &links); err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+func (r *mongoRepository) Unlink(ctx context.Context, userID, id primitive.ObjectID) error {
+	result, err := r.links.DeleteOne(ctx, bson.M{"_id": id, "userId": userID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *mongoRepository) BindChannel(ctx context.Context, ch Channel) error {
+	_, err := r.channels.UpdateOne(
+		ctx,
+		bson.M{"platform": ch.Platform, "channelId": ch.ChannelID},
+		bson.M{"$set": bson.M{"gameId": ch.GameID}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+func (r *mongoRepository) FindChannel(ctx context.Context, platform, channelID string) (Channel, error) {
+	var ch Channel
+	err := r.channels.FindOne(ctx, bson.M{"platform": platform, "channelId": channelID}).Decode(&ch)
+	return ch, err
+}
+
+func (r *mongoRepository) UnlinkUser(ctx context.Context, userID primitive.ObjectID) error {
+	_, err := r.links.DeleteMany(ctx, bson.M{"userId": userID})
+	return err
+}
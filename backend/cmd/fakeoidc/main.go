@@ -0,0 +1,28 @@
+// Command fakeoidc runs a stand-in OpenID provider for trying social login
+// locally. Point a provider in OIDC_PROVIDERS_FILE at it:
+//
+//	{"providers": [{"name": "fake", "issuer": "http://localhost:9000",
+//	  "clientId": "irl-mafia-game",
+//	  "redirectUrl": "http://localhost:8080/auth/oidc/fake/callback"}]}
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"irl-mafia-game/oidc/oidctest"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:9000", "address to listen on")
+	clientID := flag.String("client-id", "irl-mafia-game", "client ID to accept")
+	flag.Parse()
+
+	server, err := oidctest.NewServer("http://"+*addr, *clientID)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Fake OpenID provider at %s, log in as anyone with ?login_hint=<name>", server.Issuer)
+	log.Fatal(http.ListenAndServe(*addr, server))
+}
@@ -2,11 +2,23 @@ package main
 
 import (
 	"context"
+	"irl-mafia-game/account"
+	"irl-mafia-game/admin"
 	"irl-mafia-game/auth"
+	"irl-mafia-game/chat"
+	"irl-mafia-game/chatbot"
 	"irl-mafia-game/db"
+	"irl-mafia-game/friends"
 	"irl-mafia-game/game"
+	"irl-mafia-game/mail"
+	"irl-mafia-game/notifications"
+	"irl-mafia-game/oidc"
 	"irl-mafia-game/user"
+	"irl-mafia-game/webhooks"
 	"log"
+	"math/rand"
+	"os"
+	"strings"
 	"time"
 
 	_ "irl-mafia-game/docs" // Swagger docs
@@ -21,19 +33,115 @@ import (
 // @in header
 // @name Authorization
 func main() {
+	started := time.Now()
 	dbm, err := db.NewDBManager("mongodb://localhost:27017", "irl-mafia-game")
 	if err != nil {
 		log.Fatal(err)
 	}
 	defer dbm.Close(context.Background())
 
+	// Administrators are bootstrapped from the environment; they can grant
+	// the role to others through /admin
+	for _, username := range strings.Split(os.Getenv("ADMIN_USERNAMES"), ",") {
+		if username = strings.TrimSpace(username); username == "" {
+			continue
+		}
+		if err := user.GrantRole(context.Background(), dbm.UserRepo, username, user.RoleAdmin); err != nil {
+			log.Printf("Failed to make %s an admin: %v", username, err)
+		}
+	}
+
+	signingKeys, err := auth.LoadKeys()
+	if err != nil {
+		log.Fatal(err)
+	}
+	auth.SetKeys(signingKeys)
+	tokens := auth.NewTokens(dbm.TokenRepo, dbm.SessionRepo, dbm.APITokenRepo)
+
+	// Attempts are shared through Mongo unless a single instance opts to
+	// keep them in memory
+	attempts := dbm.AttemptRepo
+	if os.Getenv("LOGIN_ATTEMPT_STORE") == "memory" {
+		attempts = auth.NewMemoryAttemptStore()
+	}
+	loginGuard := auth.NewLoginGuard(attempts, dbm.LoginAudit)
+
+	mailer, err := mail.FromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var avatars user.AvatarStore
+	if os.Getenv("AVATAR_STORE") == "gridfs" {
+		avatars, err = user.NewGridFSAvatarStore(dbm.Database)
+	} else {
+		avatarDir := os.Getenv("AVATAR_DIR")
+		if avatarDir == "" {
+			avatarDir = "avatars"
+		}
+		avatars, err = user.NewDiskAvatarStore(avatarDir)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	oidcConfig, err := oidc.LoadConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	oidcLogin := oidc.NewLogin(oidcConfig, dbm.OIDCRepo, dbm.UserRepo, tokens)
+
+	engine := game.NewEngine(rand.New(rand.NewSource(time.Now().UnixNano())))
+	hub := game.NewHub()
+	gameRepo := game.NewPublishingRepository(dbm.GameRepo, hub)
+
+	var pushProvider notifications.Provider = notifications.NewExpoProvider(os.Getenv("EXPO_ACCESS_TOKEN"))
+	if os.Getenv("PUSH_PROVIDER") == "fake" {
+		pushProvider = notifications.NewFakeProvider()
+	}
+	dispatcher := notifications.NewDispatcher(dbm.DeviceRepo, dbm.UserRepo, pushProvider)
+	hub.Listen(dispatcher.GameListener())
+	go notifications.NewScheduler(gameRepo, engine, dispatcher, dbm.SettingsRepo).Run(context.Background(), time.Minute)
+
+	webhookSender := webhooks.NewSender(dbm.WebhookRepo)
+	hub.Listen(webhookSender.GameListener())
+	hub.Listen(chat.SystemListener(dbm.MessageRepo, dbm.UserRepo, hub))
+
+	accountStores := account.Stores{
+		Users:     dbm.UserRepo,
+		Games:     gameRepo,
+		Messages:  dbm.MessageRepo,
+		Devices:   dbm.DeviceRepo,
+		Settings:  dbm.SettingsRepo,
+		ChatLinks: dbm.ChatRepo,
+		Friends:   dbm.FriendRepo,
+		Tokens:    tokens,
+		Logins:    loginGuard,
+		Resets:    dbm.ResetRepo,
+		Avatars:   avatars,
+	}
+
+	go account.NewGuestCleaner(accountStores).Run(context.Background(), time.Hour)
+
 	r := gin.Default()
 
+	// Client IPs limit logins and are kept on sessions, so only our own
+	// proxies may set them with X-Forwarded-For
+	var proxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	if err := r.SetTrustedProxies(proxies); err != nil {
+		log.Fatal(err)
+	}
+
 	// Configure CORS
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:19006", "http://localhost:8081"}, // Expo dev servers
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "X-Device-Name"},
 		ExposeHeaders:    []string{"Content-Length"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
@@ -42,22 +150,123 @@ func main() {
 	// Public routes
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	r.POST("/signup", user.SignupHandler(dbm.UserRepo))
-	r.POST("/login", user.LoginHandler(dbm.UserRepo))
+	r.POST("/login", user.LoginHandler(dbm.UserRepo, tokens, loginGuard))
+	r.POST("/guests", account.GuestHandler(accountStores, engine))
+	r.POST("/token/refresh", auth.RefreshHandler(tokens))
+	r.POST("/logout", auth.LogoutHandler(tokens))
+	r.POST("/password/forgot", user.ForgotPasswordHandler(dbm.UserRepo, dbm.ResetRepo, mailer, os.Getenv("PASSWORD_RESET_URL")))
+	r.POST("/password/reset", user.ResetPasswordHandler(dbm.UserRepo, dbm.ResetRepo, tokens))
+	r.GET("/avatars/:file", user.GetAvatarHandler(avatars))
+	r.GET("/auth/oidc/providers", oidc.GetProvidersHandler(oidcLogin))
+	r.GET("/auth/oidc/:provider/login", oidc.StartLoginHandler(oidcLogin))
+	r.GET("/auth/oidc/:provider/callback", oidc.CallbackHandler(oidcLogin))
+	r.POST("/auth/oidc/signup", oidc.SignupHandler(oidcLogin))
+	r.GET("/.well-known/jwks.json", auth.JWKSHandler())
+
+	// Chat bot webhooks, only enabled when their secret is configured
+	chatBot := chatbot.NewBot(dbm.ChatRepo, dbm.UserRepo, gameRepo, engine)
+	if secret := os.Getenv("CHATBOT_SECRET"); secret != "" {
+		r.POST("/chat/local", chatbot.LocalWebhookHandler(chatBot, secret))
+	}
+	if secret := os.Getenv("TELEGRAM_SECRET"); secret != "" {
+		r.POST("/chat/telegram", chatbot.TelegramWebhookHandler(chatBot, secret))
+	}
 
 	// Protected routes
 	protected := r.Group("/")
-	protected.Use(auth.AuthMiddleware())
+	protected.Use(auth.AuthMiddleware(tokens))
+
+	// Guests can play in their games but not set anything new up until
+	// they upgrade
+	roles := user.RoleLookup(dbm.UserRepo)
+	members := protected.Group("/", auth.RequireRole(user.RoleMember, roles))
+
+	// Event streams also take the token from the query string
+	streams := r.Group("/", auth.QueryToken(), auth.AuthMiddleware(tokens))
+
+	// API tokens only reach the routes their scopes allow, and never those
+	// that manage the account itself
+	read := auth.RequireScope(auth.ScopeRead)
+	play := auth.RequireScope(auth.ScopePlay)
+	sessionOnly := auth.SessionOnly()
+	protected.POST("/logout/all", sessionOnly, auth.LogoutAllHandler(tokens))
 
 	// User routes
-	protected.GET("/users", user.GetAllUsersHandler(dbm.UserRepo))
-	protected.GET("/users/me", user.GetCurrentUserHandler(dbm.UserRepo))
+	protected.GET("/users/me", read, user.GetCurrentUserHandler(dbm.UserRepo))
+	protected.GET("/users/me/export", sessionOnly, account.ExportHandler(accountStores))
+	protected.POST("/users/me/upgrade", sessionOnly, user.UpgradeGuestHandler(dbm.UserRepo))
+	protected.DELETE("/users/me", sessionOnly, account.DeleteAccountHandler(accountStores))
+	members.POST("/users/me/password", sessionOnly, user.ChangePasswordHandler(dbm.UserRepo, tokens))
+	protected.GET("/users/me/friends", read, friends.GetFriendsHandler(dbm.FriendRepo, dbm.UserRepo))
+	protected.DELETE("/users/me/friends/:userId", play, friends.RemoveFriendHandler(dbm.FriendRepo))
+	protected.GET("/users/me/friend-requests", read, friends.GetRequestsHandler(dbm.FriendRepo, dbm.UserRepo))
+	members.POST("/users/me/friend-requests", play, friends.SendRequestHandler(dbm.FriendRepo, dbm.UserRepo))
+	members.POST("/users/me/friend-requests/:requestId/accept", play, friends.AcceptRequestHandler(dbm.FriendRepo))
+	protected.POST("/users/me/friend-requests/:requestId/decline", play, friends.DeclineRequestHandler(dbm.FriendRepo))
+	protected.PUT("/users/me/profile", play, user.UpdateProfileHandler(dbm.UserRepo))
+	members.POST("/users/me/avatar", play, user.UploadAvatarHandler(dbm.UserRepo, avatars))
+	protected.DELETE("/users/me/avatar", play, user.DeleteAvatarHandler(dbm.UserRepo, avatars))
+	protected.GET("/users/me/identities", read, oidc.GetIdentitiesHandler(dbm.UserRepo))
+	members.POST("/users/me/identities/:provider", sessionOnly, oidc.LinkIdentityHandler(oidcLogin))
+	protected.DELETE("/users/me/identities/:provider", sessionOnly, oidc.UnlinkIdentityHandler(dbm.UserRepo))
+	protected.GET("/users/me/sessions", sessionOnly, auth.GetSessionsHandler(tokens))
+	protected.DELETE("/users/me/sessions/:sessionId", sessionOnly, auth.RevokeSessionHandler(tokens))
+	protected.GET("/users/me/api-tokens", sessionOnly, auth.GetAPITokensHandler(tokens))
+	members.POST("/users/me/api-tokens", sessionOnly, auth.CreateAPITokenHandler(tokens, roles, user.RoleAdmin))
+	protected.DELETE("/users/me/api-tokens/:tokenId", sessionOnly, auth.DeleteAPITokenHandler(tokens))
+	protected.POST("/users/me/devices", sessionOnly, notifications.RegisterDeviceHandler(dbm.DeviceRepo))
+	protected.DELETE("/users/me/devices/:token", sessionOnly, notifications.RemoveDeviceHandler(dbm.DeviceRepo))
+	protected.GET("/users/me/notification-settings", read, notifications.GetSettingsHandler(dbm.SettingsRepo))
+	protected.PUT("/users/me/notification-settings", play, notifications.UpdateSettingsHandler(dbm.SettingsRepo))
+	protected.GET("/users/me/chat-links", read, chatbot.GetLinksHandler(dbm.ChatRepo))
+	members.POST("/users/me/chat-links/code", sessionOnly, chatbot.CreateLinkCodeHandler(dbm.ChatRepo))
+	protected.DELETE("/users/me/chat-links/:linkId", sessionOnly, chatbot.DeleteLinkHandler(dbm.ChatRepo))
 
 	// Game routes
-	protected.GET("/games", game.GetAllGamesHandler(dbm.GameRepo))
-	protected.POST("/games/create", game.CreateGameHandler(dbm.GameRepo, dbm.UserRepo))
-	protected.GET("/games/:id", game.GetGameHandler(dbm.GameRepo))
-	protected.POST("/games/:id/join", game.JoinGameHandler(dbm.GameRepo, dbm.UserRepo))
-	protected.GET("/games/:id/players", game.GetPlayersUsernamesHandler(dbm.GameRepo, dbm.UserRepo))
+	protected.GET("/games", read, game.GetAllGamesHandler(gameRepo))
+	members.POST("/games/create", play, game.CreateGameHandler(gameRepo, dbm.UserRepo, dbm.FriendRepo, engine))
+	protected.GET("/games/:id", read, game.GetGameHandler(gameRepo))
+	protected.POST("/games/:id/join", play, game.JoinGameHandler(gameRepo, dbm.UserRepo, dbm.FriendRepo, engine))
+	protected.GET("/games/:id/players", read, game.GetPlayersUsernamesHandler(gameRepo, dbm.UserRepo))
+	protected.POST("/games/:id/actions", play, game.ActionHandler(gameRepo, engine))
+	members.POST("/games/:id/bots", play, game.AddBotHandler(gameRepo, engine))
+	streams.GET("/games/:id/events", read, game.StreamEventsHandler(gameRepo, hub))
+	streams.GET("/games/:id/events/ws", read, game.StreamEventsWebSocketHandler(gameRepo, hub))
+	protected.GET("/games/:id/standings", read, game.GetStandingsHandler(gameRepo, engine))
+	protected.GET("/games/:id/report", read, game.GetReportHandler(gameRepo))
+	protected.PUT("/games/:id/schedule", play, game.UpdateScheduleHandler(gameRepo))
+	members.POST("/games/:id/invite", play, game.CreateInviteHandler(gameRepo))
+	protected.DELETE("/games/:id/invite", play, game.RevokeInviteHandler(gameRepo))
+	protected.POST("/invites/:code/join", play, game.JoinByInviteHandler(gameRepo, dbm.UserRepo, dbm.FriendRepo, engine))
+	protected.GET("/games/:id/disputes", read, game.GetDisputesHandler(gameRepo))
+	protected.POST("/games/:id/disputes", play, game.OpenDisputeHandler(gameRepo, engine))
+	protected.POST("/games/:id/disputes/:disputeId/votes", play, game.VoteDisputeHandler(gameRepo, engine))
+	protected.POST("/games/:id/disputes/:disputeId/resolve", play, game.ResolveDisputeHandler(gameRepo, engine))
+
+	// Chat routes
+	protected.GET("/games/:id/chat", read, chat.GetMessagesHandler(dbm.MessageRepo, gameRepo))
+	protected.POST("/games/:id/chat", play, chat.SendMessageHandler(dbm.MessageRepo, gameRepo, hub))
+	protected.POST("/games/:id/chat/:messageId/reactions", play, chat.ReactHandler(dbm.MessageRepo, gameRepo, hub))
+	protected.DELETE("/games/:id/chat/:messageId", play, chat.DeleteMessageHandler(dbm.MessageRepo, gameRepo, hub))
+
+	// Webhook routes
+	protected.GET("/games/:id/webhooks", read, webhooks.GetWebhooksHandler(dbm.WebhookRepo, gameRepo))
+	members.POST("/games/:id/webhooks", play, webhooks.CreateWebhookHandler(dbm.WebhookRepo, gameRepo))
+	protected.DELETE("/games/:id/webhooks/:webhookId", play, webhooks.DeleteWebhookHandler(dbm.WebhookRepo, gameRepo))
+	members.POST("/games/:id/webhooks/:webhookId/ping", play, webhooks.PingWebhookHandler(dbm.WebhookRepo, gameRepo, webhookSender))
+	protected.GET("/games/:id/webhooks/:webhookId/deliveries", read, webhooks.GetDeliveriesHandler(dbm.WebhookRepo, gameRepo))
+
+	// Admin routes
+	adminGroup := protected.Group("/admin")
+	adminGroup.Use(auth.RequireScope(auth.ScopeAdmin), auth.RequireRole(user.RoleAdmin, roles))
+	adminGroup.GET("/users", admin.ListUsersHandler(dbm.UserRepo))
+	adminGroup.POST("/users/:userId/disable", admin.SetDisabledHandler(dbm.UserRepo, tokens, true))
+	adminGroup.POST("/users/:userId/enable", admin.SetDisabledHandler(dbm.UserRepo, tokens, false))
+	adminGroup.PUT("/users/:userId/roles", admin.SetRolesHandler(dbm.UserRepo))
+	adminGroup.GET("/games/:id", admin.GetGameStateHandler(gameRepo))
+	adminGroup.GET("/games/:id/report", admin.GetGameReportHandler(gameRepo))
+	adminGroup.POST("/games/:id/finish", admin.FinishGameHandler(gameRepo, engine))
+	adminGroup.GET("/stats", admin.StatsHandler(dbm.UserRepo, gameRepo, started))
 
 	r.Run(":8080")
 }
@@ -0,0 +1,184 @@
+// Command simulate plays headless bot-only games with the real engine and
+// prints statistics for tuning Rules.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"irl-mafia-game/game"
+	"log"
+	"math/rand"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type stats struct {
+	games          int
+	finished       int
+	days           int
+	initialWins    int
+	guesses        int
+	correctGuesses int
+	guessTiles     int // tiles gained minus tiles lost by guessing
+	claims         int
+	claimTiles     int
+	decays         int
+	moves          int
+	winningScore   int
+	margin         int // winner's lead over the runner-up
+}
+
+func main() {
+	games := flag.Int("games", 1000, "number of games to simulate")
+	players := flag.Int("players", 5, "players per game")
+	size := flag.Int("size", 3, "board size")
+	seed := flag.Int64("seed", 1, "random seed")
+	maxDays := flag.Int("max-days", 365, "give up on games that run longer than this")
+	freeCenter := flag.Bool("free-center", false, "free center tile on odd boards")
+
+	defaults := game.DefaultRules()
+	mode := flag.String("mode", defaults.Mode, "rules mode: bingo or points")
+	days := flag.Int("days", defaults.Days, "length of a points game")
+	decay := flag.Int("decay", defaults.DecayDays, "Cooties lose a tile every this many days, 0 never")
+	transfer := flag.Int("transfer", defaults.TransferDays, "Cooties move on after this many days, 0 never")
+	items := flag.Bool("items", defaults.Items, "award power-ups")
+	streakDays := flag.Int("streak-days", defaults.StreakDays, "days in a row that earn an item")
+	tilePoints := flag.Int("tile-points", defaults.TilePoints, "points per claimed tile")
+	linePoints := flag.Int("line-points", defaults.LinePoints, "points per completed line")
+	guessPoints := flag.Int("guess-points", defaults.GuessPoints, "points per correct guess")
+	throttle := flag.Bool("throttle", defaults.Throttle, "refuse repeat mutual claims")
+	flag.Parse()
+
+	rules := defaults
+	rules.Mode = *mode
+	rules.Days = *days
+	rules.DecayDays = *decay
+	rules.TransferDays = *transfer
+	rules.Items = *items
+	rules.StreakDays = *streakDays
+	rules.TilePoints = *tilePoints
+	rules.LinePoints = *linePoints
+	rules.GuessPoints = *guessPoints
+	rules.Throttle = *throttle
+	if err := rules.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	if *players < 2 || *size < 3 {
+		log.Fatal("need at least 2 players and a board size of at least 3")
+	}
+
+	start := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	clock := start
+	engine := game.NewEngine(rand.New(rand.NewSource(*seed)))
+	engine.SetClock(func() time.Time { return clock })
+
+	var s stats
+	for i := 0; i < *games; i++ {
+		clock = start
+		g := game.Game{
+			ID:         primitive.NewObjectID(),
+			BoardSize:  *size,
+			Status:     "active",
+			CreatedAt:  start,
+			Patterns:   game.DefaultPatterns,
+			FreeCenter: *freeCenter && *size%2 == 1,
+			Rules:      rules,
+		}
+		for p := 0; p < *players; p++ {
+			if _, err := engine.AddBot(&g, fmt.Sprintf("bot %d", p+1)); err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		var initial primitive.ObjectID
+		for _, p := range g.PlayerStates {
+			if p.Cooties {
+				initial = p.User
+			}
+		}
+
+		day := 0
+		for ; g.Status == "active" && day <= *maxDays; day++ {
+			clock = start.Add(time.Duration(day) * 24 * time.Hour)
+			engine.Advance(&g)
+		}
+
+		s.games++
+		if g.Status == "finished" {
+			s.finished++
+			// A bingo is won during its last day, a points game ends as
+			// the day after its last one starts
+			if rules.Mode == game.ModePoints {
+				s.days += rules.Days
+			} else {
+				s.days += day
+			}
+			if g.Winner == initial {
+				s.initialWins++
+			}
+			if standings := g.Standings(); len(standings) > 1 {
+				s.winningScore += standings[0].Score
+				s.margin += standings[0].Score - standings[1].Score
+			}
+		}
+		s.add(g)
+	}
+
+	s.print(*players)
+}
+
+func (s *stats) add(g game.Game) {
+	lost := make(map[int]int)
+	for _, ev := range g.Events {
+		if ev.Type == game.EventTileLost {
+			lost[ev.Cause] += len(ev.Tiles)
+		}
+	}
+
+	for _, ev := range g.Events {
+		switch ev.Type {
+		case game.EventClaim:
+			s.claims++
+			s.claimTiles += len(ev.Tiles)
+		case game.EventGuess:
+			s.guesses++
+			if ev.Correct {
+				s.correctGuesses++
+			}
+			s.guessTiles += len(ev.Tiles) - lost[ev.Seq]
+		case game.EventCootiesDecay:
+			s.decays++
+		case game.EventCootiesMoved:
+			s.moves++
+		}
+	}
+}
+
+func (s *stats) print(players int) {
+	fmt.Printf("games:                 %d (%d finished)\n", s.games, s.finished)
+	if s.finished > 0 {
+		fmt.Printf("average length:        %.1f days\n", float64(s.days)/float64(s.finished))
+		fmt.Printf("initial Cooties wins:  %.1f%% (fair share %.1f%%)\n",
+			100*float64(s.initialWins)/float64(s.finished), 100/float64(players))
+		fmt.Printf("top score:             %.1f (%.1f ahead)\n",
+			float64(s.winningScore)/float64(s.finished), float64(s.margin)/float64(s.finished))
+	}
+	if s.games > 0 {
+		fmt.Printf("cooties decay/game:    %.1f\n", float64(s.decays)/float64(s.games))
+		fmt.Printf("cooties moves/game:    %.1f\n", float64(s.moves)/float64(s.games))
+	}
+	if s.claims > 0 {
+		fmt.Printf("tiles per claim:       %.2f\n", float64(s.claimTiles)/float64(s.claims))
+	}
+	if s.guesses > 0 {
+		perGuess := float64(s.guessTiles) / float64(s.guesses)
+		fmt.Printf("correct guesses:       %.1f%%\n", 100*float64(s.correctGuesses)/float64(s.guesses))
+		fmt.Printf("net tiles per guess:   %.2f\n", perGuess)
+		if s.claims > 0 && perGuess > float64(s.claimTiles)/float64(s.claims) {
+			fmt.Println("guessing beats claiming on average")
+		} else {
+			fmt.Println("claiming beats guessing on average")
+		}
+	}
+}
@@ -4,8 +4,15 @@ package db
 import (
 	"context"
 	"fmt"
+	"irl-mafia-game/auth"
+	"irl-mafia-game/chat"
+	"irl-mafia-game/chatbot"
+	"irl-mafia-game/friends"
 	"irl-mafia-game/game"
+	"irl-mafia-game/notifications"
+	"irl-mafia-game/oidc"
 	"irl-mafia-game/user"
+	"irl-mafia-game/webhooks"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -14,10 +21,23 @@ import (
 )
 
 type DBManager struct {
-	Client   *mongo.Client
-	Database *mongo.Database
-	UserRepo user.UserRepository
-	GameRepo game.GameRepository
+	Client       *mongo.Client
+	Database     *mongo.Database
+	UserRepo     user.UserRepository
+	ResetRepo    user.ResetRepository
+	GameRepo     game.GameRepository
+	DeviceRepo   notifications.DeviceRepository
+	SettingsRepo notifications.SettingsRepository
+	WebhookRepo  webhooks.WebhookRepository
+	ChatRepo     chatbot.LinkRepository
+	MessageRepo  chat.MessageRepository
+	TokenRepo    auth.TokenRepository
+	SessionRepo  auth.SessionRepository
+	APITokenRepo auth.APITokenRepository
+	AttemptRepo  auth.AttemptStore
+	LoginAudit   auth.AuditLog
+	OIDCRepo     oidc.StateRepository
+	FriendRepo   friends.FriendRepository
 }
 
 // NewDBManager connects to Mongo and sets up repositories
@@ -49,11 +69,246 @@ func NewDBManager(uri, dbName string) (*DBManager, error) {
 		return nil, fmt.Errorf("failed to create index: %w", err)
 	}
 
+	// Ensure each push token belongs to a single device entry
+	_, err = db.Collection("devices").Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{Keys: bson.M{"token": 1}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.M{"userId": 1}},
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create index: %w", err)
+	}
+
+	_, err = db.Collection("webhook_deliveries").Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{{Key: "webhookId", Value: 1}, {Key: "createdAt", Value: -1}},
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create index: %w", err)
+	}
+
+	_, err = db.Collection("chat_links").Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{Keys: bson.D{{Key: "platform", Value: 1}, {Key: "chatUserId", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.M{"userId": 1}},
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create index: %w", err)
+	}
+
+	// Expired link codes are removed by Mongo
+	_, err = db.Collection("chat_link_codes").Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.M{"expiresAt": 1},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create index: %w", err)
+	}
+
+	_, err = db.Collection("chat_channels").Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "platform", Value: 1}, {Key: "channelId", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create index: %w", err)
+	}
+
+	_, err = db.Collection("chat_messages").Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{{Key: "gameId", Value: 1}, {Key: "_id", Value: -1}},
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create index: %w", err)
+	}
+
+	// Scheduled runs only need to be remembered for a few days
+	_, err = db.Collection("notification_runs").Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.M{"createdAt": 1},
+		Options: options.Index().SetExpireAfterSeconds(7 * 24 * 60 * 60),
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create index: %w", err)
+	}
+
+	_, err = db.Collection("refresh_tokens").Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{Keys: bson.M{"hash": 1}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.M{"family": 1}},
+		{Keys: bson.M{"userId": 1}},
+		{Keys: bson.M{"expiresAt": 1}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create index: %w", err)
+	}
+
+	_, err = db.Collection("users").Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.M{"email": 1},
+		Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"email": bson.M{"$type": "string"}}),
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create index: %w", err)
+	}
+
+	_, err = db.Collection("password_resets").Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{Keys: bson.M{"hash": 1}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.M{"userId": 1}},
+		{Keys: bson.M{"expiresAt": 1}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create index: %w", err)
+	}
+
+	// The identities index used to be non-unique; it has to be dropped
+	// before it can be recreated with the same keys
+	specs, err := db.Collection("users").Indexes().ListSpecifications(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list indexes: %w", err)
+	}
+	for _, spec := range specs {
+		if spec.Name == "identities.provider_1_identities.subject_1" && (spec.Unique == nil || !*spec.Unique) {
+			if _, err := db.Collection("users").Indexes().DropOne(context.Background(), spec.Name); err != nil {
+				return nil, fmt.Errorf("failed to drop index: %w", err)
+			}
+		}
+	}
+
+	// One external login belongs to at most one account; accounts whose
+	// identities were all unlinked keep an empty array and stay out of it
+	_, err = db.Collection("users").Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{{Key: "identities.provider", Value: 1}, {Key: "identities.subject", Value: 1}},
+		Options: options.Index().
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"identities.subject": bson.M{"$exists": true}}),
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create index: %w", err)
+	}
+
+	for _, name := range []string{"oidc_states", "oidc_tickets"} {
+		_, err = db.Collection(name).Indexes().CreateOne(context.Background(), mongo.IndexModel{
+			Keys:    bson.M{"expiresAt": 1},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		})
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to create index: %w", err)
+		}
+	}
+
+	// Older accounts carry a placeholder friends field from before the
+	// friend graph had its own collection
+	_, err = db.Collection("users").UpdateMany(context.Background(),
+		bson.M{"friends": bson.M{"$exists": true}},
+		bson.M{"$unset": bson.M{"friends": ""}},
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to clean up users: %w", err)
+	}
+
+	_, err = db.Collection("friendships").Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{Keys: bson.D{{Key: "userA", Value: 1}, {Key: "userB", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "userB", Value: 1}, {Key: "status", Value: 1}}},
+		{Keys: bson.D{{Key: "userA", Value: 1}, {Key: "status", Value: 1}}},
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create index: %w", err)
+	}
+
+	_, err = db.Collection("login_attempts").Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.M{"lastFailure": 1},
+		Options: options.Index().SetExpireAfterSeconds(24 * 60 * 60),
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create index: %w", err)
+	}
+
+	// Failed logins are kept for 90 days
+	_, err = db.Collection("login_failures").Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{Keys: bson.D{{Key: "username", Value: 1}, {Key: "at", Value: -1}}},
+		{Keys: bson.D{{Key: "ip", Value: 1}, {Key: "at", Value: -1}}},
+		{Keys: bson.M{"at": 1}, Options: options.Index().SetExpireAfterSeconds(90 * 24 * 60 * 60)},
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create index: %w", err)
+	}
+
+	// Sessions are kept until their last refresh token expires
+	_, err = db.Collection("sessions").Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{Keys: bson.D{{Key: "userId", Value: 1}, {Key: "lastUsed", Value: -1}}},
+		{Keys: bson.M{"expiresAt": 1}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create index: %w", err)
+	}
+
+	_, err = db.Collection("api_tokens").Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{Keys: bson.M{"hash": 1}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "userId", Value: 1}, {Key: "createdAt", Value: -1}}},
+		{Keys: bson.M{"expiresAt": 1}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create index: %w", err)
+	}
+
+	_, err = db.Collection("games").Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.M{"invite": 1},
+		Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"invite": bson.M{"$type": "string"}}),
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create index: %w", err)
+	}
+
+	// Games saved before versioning start at version 0, so they can be
+	// updated by version like the rest
+	_, err = db.Collection("games").UpdateMany(context.Background(),
+		bson.M{"version": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"version": 0}},
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to version games: %w", err)
+	}
+
+	_, err = db.Collection("users").Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.M{"lastSeen": 1},
+		Options: options.Index().SetPartialFilterExpression(bson.M{"guest": true}),
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create index: %w", err)
+	}
+
 	return &DBManager{
-		Client:   client,
-		Database: db,
-		UserRepo: user.NewMongoRepository(db.Collection("users")),
-		GameRepo: game.NewMongoRepository(db.Collection("games")),
+		Client:       client,
+		Database:     db,
+		UserRepo:     user.NewMongoRepository(db.Collection("users")),
+		ResetRepo:    user.NewMongoResetRepository(db.Collection("password_resets")),
+		GameRepo:     game.NewMongoRepository(db.Collection("games")),
+		DeviceRepo:   notifications.NewMongoRepository(db.Collection("devices")),
+		SettingsRepo: notifications.NewMongoSettingsRepository(db.Collection("notification_settings"), db.Collection("notification_runs")),
+		WebhookRepo:  webhooks.NewMongoRepository(db.Collection("webhooks"), db.Collection("webhook_deliveries")),
+		ChatRepo:     chatbot.NewMongoRepository(db.Collection("chat_links"), db.Collection("chat_link_codes"), db.Collection("chat_channels")),
+		MessageRepo:  chat.NewMongoRepository(db.Collection("chat_messages")),
+		TokenRepo:    auth.NewMongoTokenRepository(db.Collection("refresh_tokens")),
+		SessionRepo:  auth.NewMongoSessionRepository(db.Collection("sessions")),
+		APITokenRepo: auth.NewMongoAPITokenRepository(db.Collection("api_tokens")),
+		AttemptRepo:  auth.NewMongoAttemptStore(db.Collection("login_attempts")),
+		LoginAudit:   auth.NewMongoAuditLog(db.Collection("login_failures")),
+		OIDCRepo:     oidc.NewMongoRepository(db.Collection("oidc_states"), db.Collection("oidc_tickets")),
+		FriendRepo:   friends.NewMongoRepository(db.Collection("friendships")),
 	}, nil
 }
 
@@ -0,0 +1,292 @@
+package friends
+
+import (
+	"irl-mafia-game/auth"
+	"irl-mafia-game/user"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type FriendRequestRequest struct {
+	UserID   string `json:"userId"`
+	Username string `json:"username"` // used when userId is empty
+}
+
+type FriendResponse struct {
+	ID       string               `json:"id"`
+	Username string               `json:"username"`
+	Profile  user.ProfileResponse `json:"profile"`
+	Since    time.Time            `json:"since"`
+}
+
+type RequestResponse struct {
+	ID        string               `json:"id"`
+	UserID    string               `json:"userId"` // the other user
+	Username  string               `json:"username"`
+	Profile   user.ProfileResponse `json:"profile"`
+	CreatedAt time.Time            `json:"createdAt"`
+}
+
+type RequestsResponse struct {
+	Incoming []RequestResponse `json:"incoming"`
+	Outgoing []RequestResponse `json:"outgoing"`
+}
+
+// GetFriendsHandler godoc
+// @Summary List friends
+// @Description Get the current user's friends
+// @Tags friends
+// @Produce json
+// @Success 200 {array} FriendResponse
+// @Router /users/me/friends [get]
+// @Security BearerAuth
+func GetFriendsHandler(repo FriendRepository, users user.UserRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userObjID, ok := auth.CurrentUser(c)
+		if !ok {
+			return
+		}
+
+		friendships, err := repo.FindByUser(c.Request.Context(), userObjID, StatusAccepted)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		friends := []FriendResponse{}
+		for _, f := range friendships {
+			u, err := users.FindUserWithID(c.Request.Context(), f.Other(userObjID))
+			if err != nil {
+				continue
+			}
+			friends = append(friends, FriendResponse{
+				ID:       u.ID.Hex(),
+				Username: u.Username,
+				Profile:  u.Profile.Response(),
+				Since:    f.AcceptedAt,
+			})
+		}
+		c.JSON(http.StatusOK, friends)
+	}
+}
+
+// GetRequestsHandler godoc
+// @Summary List friend requests
+// @Description Get pending friend requests sent to and by the current user
+// @Tags friends
+// @Produce json
+// @Success 200 {object} RequestsResponse
+// @Router /users/me/friend-requests [get]
+// @Security BearerAuth
+func GetRequestsHandler(repo FriendRepository, users user.UserRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userObjID, ok := auth.CurrentUser(c)
+		if !ok {
+			return
+		}
+
+		pending, err := repo.FindByUser(c.Request.Context(), userObjID, StatusPending)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		resp := RequestsResponse{Incoming: []RequestResponse{}, Outgoing: []RequestResponse{}}
+		for _, f := range pending {
+			u, err := users.FindUserWithID(c.Request.Context(), f.Other(userObjID))
+			if err != nil {
+				continue
+			}
+			r := RequestResponse{
+				ID:        f.ID.Hex(),
+				UserID:    u.ID.Hex(),
+				Username:  u.Username,
+				Profile:   u.Profile.Response(),
+				CreatedAt: f.CreatedAt,
+			}
+			if f.Requester == userObjID {
+				resp.Outgoing = append(resp.Outgoing, r)
+			} else {
+				resp.Incoming = append(resp.Incoming, r)
+			}
+		}
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+// SendRequestHandler godoc
+// @Summary Send a friend request
+// @Description Ask another user, by ID or username, to be friends. If they already asked you, you become friends right away.
+// @Tags friends
+// @Accept json
+// @Produce json
+// @Param request body FriendRequestRequest true "User to befriend"
+// @Success 200 {object} map[string]string
+// @Router /users/me/friend-requests [post]
+// @Security BearerAuth
+func SendRequestHandler(repo FriendRepository, users user.UserRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userObjID, ok := auth.CurrentUser(c)
+		if !ok {
+			return
+		}
+
+		var req FriendRequestRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		var target user.User
+		var err error
+		switch {
+		case req.UserID != "":
+			targetID, perr := primitive.ObjectIDFromHex(req.UserID)
+			if perr != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+				return
+			}
+			target, err = users.FindUserWithID(c.Request.Context(), targetID)
+		case req.Username != "":
+			target, err = users.FindUserWithUsername(c.Request.Context(), req.Username)
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "userId or username is required"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			return
+		}
+
+		f, err := repo.Request(c.Request.Context(), userObjID, target.ID)
+		if err == ErrSelf || err == ErrAlreadyExists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"id": f.ID.Hex(), "status": f.Status})
+	}
+}
+
+// AcceptRequestHandler godoc
+// @Summary Accept a friend request
+// @Description Accept a pending request sent to the current user
+// @Tags friends
+// @Produce json
+// @Param requestId path string true "Request ID"
+// @Success 200 {object} map[string]string
+// @Router /users/me/friend-requests/{requestId}/accept [post]
+// @Security BearerAuth
+func AcceptRequestHandler(repo FriendRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userObjID, f, ok := pendingRequest(c, repo)
+		if !ok {
+			return
+		}
+		if f.Requester == userObjID {
+			c.JSON(http.StatusForbidden, gin.H{"error": "you can't accept your own request"})
+			return
+		}
+
+		if _, err := repo.Accept(c.Request.Context(), f.ID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": StatusAccepted})
+	}
+}
+
+// DeclineRequestHandler godoc
+// @Summary Decline or cancel a friend request
+// @Description Decline a request sent to the current user, or withdraw one they sent
+// @Tags friends
+// @Produce json
+// @Param requestId path string true "Request ID"
+// @Success 200 {object} map[string]string
+// @Router /users/me/friend-requests/{requestId}/decline [post]
+// @Security BearerAuth
+func DeclineRequestHandler(repo FriendRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_, f, ok := pendingRequest(c, repo)
+		if !ok {
+			return
+		}
+
+		if err := repo.Delete(c.Request.Context(), f.ID); err != nil && err != mongo.ErrNoDocuments {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "declined"})
+	}
+}
+
+// RemoveFriendHandler godoc
+// @Summary Remove a friend
+// @Description End a friendship with another user
+// @Tags friends
+// @Produce json
+// @Param userId path string true "Friend's user ID"
+// @Success 200 {object} map[string]string
+// @Router /users/me/friends/{userId} [delete]
+// @Security BearerAuth
+func RemoveFriendHandler(repo FriendRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userObjID, ok := auth.CurrentUser(c)
+		if !ok {
+			return
+		}
+		friendID, err := primitive.ObjectIDFromHex(c.Param("userId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+			return
+		}
+
+		f, err := repo.Find(c.Request.Context(), userObjID, friendID)
+		if err == mongo.ErrNoDocuments || (err == nil && f.Status != StatusAccepted) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not friends"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := repo.Delete(c.Request.Context(), f.ID); err != nil && err != mongo.ErrNoDocuments {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "removed"})
+	}
+}
+
+// pendingRequest loads the pending request in the path, checking the
+// current user is part of it.
+func pendingRequest(c *gin.Context, repo FriendRepository) (primitive.ObjectID, Friendship, bool) {
+	userObjID, ok := auth.CurrentUser(c)
+	if !ok {
+		return primitive.NilObjectID, Friendship{}, false
+	}
+	requestID, err := primitive.ObjectIDFromHex(c.Param("requestId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request ID"})
+		return primitive.NilObjectID, Friendship{}, false
+	}
+
+	f, err := repo.FindByID(c.Request.Context(), requestID)
+	if err == mongo.ErrNoDocuments || (err == nil && (!f.Includes(userObjID) || f.Status != StatusPending)) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "friend request not found"})
+		return primitive.NilObjectID, Friendship{}, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return primitive.NilObjectID, Friendship{}, false
+	}
+	return userObjID, f, true
+}
@@ -0,0 +1,43 @@
+package friends
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	StatusPending  = "pending"
+	StatusAccepted = "accepted"
+)
+
+// Friendship is one edge of the friend graph. The two users are stored in
+// ID order so each pair has a single document, whoever asked first.
+type Friendship struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty"`
+	UserA      primitive.ObjectID `bson:"userA"`
+	UserB      primitive.ObjectID `bson:"userB"`
+	Requester  primitive.ObjectID `bson:"requester"`
+	Status     string             `bson:"status"`
+	CreatedAt  time.Time          `bson:"createdAt"`
+	AcceptedAt time.Time          `bson:"acceptedAt,omitempty"`
+}
+
+// Other is the user on the other side of the friendship from id.
+func (f Friendship) Other(id primitive.ObjectID) primitive.ObjectID {
+	if f.UserA == id {
+		return f.UserB
+	}
+	return f.UserA
+}
+
+func (f Friendship) Includes(id primitive.ObjectID) bool {
+	return f.UserA == id || f.UserB == id
+}
+
+func pair(a, b primitive.ObjectID) (primitive.ObjectID, primitive.ObjectID) {
+	if a.Hex() < b.Hex() {
+		return a, b
+	}
+	return b, a
+}
@@ -0,0 +1,134 @@
+package friends
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	ErrSelf          = errors.New("you can't befriend yourself")
+	ErrAlreadyExists = errors.New("already friends or request pending")
+)
+
+type FriendRepository interface {
+	// Request asks to be friends. If the other user already asked, the
+	// friendship is accepted instead.
+	Request(ctx context.Context, from, to primitive.ObjectID) (Friendship, error)
+	FindByID(ctx context.Context, id primitive.ObjectID) (Friendship, error)
+	Accept(ctx context.Context, id primitive.ObjectID) (Friendship, error)
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	Find(ctx context.Context, a, b primitive.ObjectID) (Friendship, error)
+	// FindByUser lists a user's friendships with the given status.
+	FindByUser(ctx context.Context, userID primitive.ObjectID, status string) ([]Friendship, error)
+	AreFriends(ctx context.Context, a, b primitive.ObjectID) (bool, error)
+	DeleteByUser(ctx context.Context, userID primitive.ObjectID) error
+}
+
+type mongoRepository struct {
+	col *mongo.Collection
+}
+
+func NewMongoRepository(col *mongo.Collection) FriendRepository {
+	return &mongoRepository{col: col}
+}
+
+func (r *mongoRepository) Request(ctx context.Context, from, to primitive.ObjectID) (Friendship, error) {
+	if from == to {
+		return Friendship{}, ErrSelf
+	}
+
+	existing, err := r.Find(ctx, from, to)
+	if err == nil {
+		if existing.Status == StatusPending && existing.Requester == to {
+			return r.Accept(ctx, existing.ID)
+		}
+		return existing, ErrAlreadyExists
+	}
+	if err != mongo.ErrNoDocuments {
+		return Friendship{}, err
+	}
+
+	a, b := pair(from, to)
+	f := Friendship{UserA: a, UserB: b, Requester: from, Status: StatusPending, CreatedAt: time.Now()}
+	res, err := r.col.InsertOne(ctx, f)
+	if mongo.IsDuplicateKeyError(err) {
+		return Friendship{}, ErrAlreadyExists
+	}
+	if err != nil {
+		return Friendship{}, err
+	}
+	f.ID = res.InsertedID.(primitive.ObjectID)
+	return f, nil
+}
+
+func (r *mongoRepository) FindByID(ctx context.Context, id primitive.ObjectID) (Friendship, error) {
+	var f Friendship
+	err := r.col.FindOne(ctx, bson.M{"_id": id}).Decode(&f)
+	return f, err
+}
+
+func (r *mongoRepository) Accept(ctx context.Context, id primitive.ObjectID) (Friendship, error) {
+	var f Friendship
+	err := r.col.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": id, "status": StatusPending},
+		bson.M{"$set": bson.M{"status": StatusAccepted, "acceptedAt": time.Now()}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&f)
+	return f, err
+}
+
+func (r *mongoRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := r.col.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *mongoRepository) Find(ctx context.Context, a, b primitive.ObjectID) (Friendship, error) {
+	a, b = pair(a, b)
+	var f Friendship
+	err := r.col.FindOne(ctx, bson.M{"userA": a, "userB": b}).Decode(&f)
+	return f, err
+}
+
+func (r *mongoRepository) FindByUser(ctx context.Context, userID primitive.ObjectID, status string) ([]Friendship, error) {
+	var friendships []Friendship
+	cursor, err := r.col.Find(ctx, bson.M{
+		"$or":    bson.A{bson.M{"userA": userID}, bson.M{"userB": userID}},
+		"status": status,
+	}, options.Find().SetSort(bson.M{"createdAt": -1}))
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(ctx, &friendships); err != nil {
+		return nil, err
+	}
+	return friendships, nil
+}
+
+func (r *mongoRepository) AreFriends(ctx context.Context, a, b primitive.ObjectID) (bool, error) {
+	f, err := r.Find(ctx, a, b)
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return f.Status == StatusAccepted, nil
+}
+
+func (r *mongoRepository) DeleteByUser(ctx context.Context, userID primitive.ObjectID) error {
+	_, err := r.col.DeleteMany(ctx, bson.M{"$or": bson.A{bson.M{"userA": userID}, bson.M{"userB": userID}}})
+	return err
+}
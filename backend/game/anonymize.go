@@ -0,0 +1,33 @@
+package game
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+const DeletedPlayerName = "Deleted player"
+
+// Anonymize cuts a deleted account out of a game. Its board, claims and
+// events stay so scores and history still add up; the host role passes to
+// the next remaining player.
+func (g *Game) Anonymize(userID primitive.ObjectID) {
+	for i := range g.PlayerStates {
+		if g.PlayerStates[i].User == userID {
+			g.PlayerStates[i].PlayerName = DeletedPlayerName
+			g.PlayerStates[i].Deleted = true
+		}
+	}
+	for i := range g.Disputes {
+		if g.Disputes[i].Disputer == userID {
+			g.Disputes[i].Reason = ""
+		}
+	}
+
+	if g.Host != userID {
+		return
+	}
+	g.Host = primitive.NilObjectID
+	for _, p := range g.PlayerStates {
+		if !p.Bot && !p.Deleted {
+			g.Host = p.User
+			return
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package game
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// botGuessChance is how often a bot guesses instead of claiming.
+const botGuessChance = 0.2
+
+// AddBot seats a bot player. Bots get a generated ID in place of a user
+// and play through the same engine as everyone else.
+func (e *Engine) AddBot(g *Game, name string) (primitive.ObjectID, error) {
+	if g.Status != "active" {
+		return primitive.NilObjectID, ErrGameNotActive
+	}
+	if g.Started() {
+		return primitive.NilObjectID, ErrGameStarted
+	}
+
+	id := primitive.NewObjectID()
+	g.Players = append(g.Players, id)
+	g.PlayerStates = append(g.PlayerStates, Player{PlayerName: name, User: id, Bot: true})
+	e.Deal(g)
+	return id, nil
+}
+
+// PlayBots takes today's action for every bot that hasn't acted yet. It
+// reports whether any bot acted.
+func (e *Engine) PlayBots(g *Game) bool {
+	acted := false
+	today := g.Day(e.now())
+	for i := range g.PlayerStates {
+		bot := &g.PlayerStates[i]
+		if g.Status != "active" {
+			break
+		}
+		if !bot.Bot || (!bot.LastAction.IsZero() && g.Day(bot.LastAction) == today) {
+			continue
+		}
+		if _, err := e.apply(g, bot.User, e.botAction(g, bot)); err == nil {
+			acted = true
+			continue
+		}
+		// A refused claim, for example a throttled one, falls back to a guess
+		if _, err := e.apply(g, bot.User, e.botGuess(g, bot)); err == nil {
+			acted = true
+		}
+	}
+	return acted
+}
+
+// botAction picks a bot's move. Bots can't meet people, so they claim
+// other bots or claim back players who claimed them today, and now and
+// then guess who has Cooties.
+func (e *Engine) botAction(g *Game, bot *Player) ActionRequest {
+	if e.rng.Float64() < botGuessChance {
+		return e.botGuess(g, bot)
+	}
+
+	claimedBy := make(map[primitive.ObjectID]bool)
+	today := g.Day(e.now())
+	for _, ev := range g.Events {
+		if ev.Type == EventClaim && !ev.Reverted && ev.Target == bot.User && g.Day(ev.Time) == today {
+			claimedBy[ev.Actor] = true
+		}
+	}
+
+	var candidates []primitive.ObjectID
+	for _, p := range g.PlayerStates {
+		if p.User == bot.User || !(p.Bot || claimedBy[p.User]) || !hasUnclaimed(bot.Board, p.User) {
+			continue
+		}
+		candidates = append(candidates, p.User)
+	}
+	if len(candidates) == 0 {
+		return e.botGuess(g, bot)
+	}
+	target := candidates[e.rng.Intn(len(candidates))]
+	return ActionRequest{Action: ActionClaim, TargetID: target.Hex()}
+}
+
+func (e *Engine) botGuess(g *Game, bot *Player) ActionRequest {
+	var others []primitive.ObjectID
+	for _, p := range g.PlayerStates {
+		if p.User != bot.User {
+			others = append(others, p.User)
+		}
+	}
+	if len(others) == 0 {
+		return ActionRequest{Action: ActionGuess}
+	}
+	target := others[e.rng.Intn(len(others))]
+	return ActionRequest{Action: ActionGuess, TargetID: target.Hex()}
+}
+
+func hasUnclaimed(board []Tile, userID primitive.ObjectID) bool {
+	for _, t := range board {
+		if !t.Claimed && t.FriendID == userID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package game
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestAddBot(t *testing.T) {
+	te := newTestEngine()
+	g := te.newGame(2, Rules{Mode: ModeBingo})
+
+	id, err := te.AddBot(g, "Robo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bot := g.player(id)
+	if bot == nil || !bot.Bot || bot.PlayerName != "Robo" || len(g.Players) != 3 {
+		t.Fatalf("players = %+v, want Robo seated", g.PlayerStates)
+	}
+	// Everyone is dealt again so the bot shows up on their boards
+	for _, p := range g.PlayerStates {
+		if p.User != id && !hasUnclaimed(p.Board, id) {
+			t.Errorf("%v has no tile for the bot", p.User)
+		}
+	}
+
+	if _, err := te.Apply(g, g.Players[0], claimRequest(id)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := te.AddBot(g, "Late"); err != ErrGameStarted {
+		t.Errorf("err = %v once the game started, want %v", err, ErrGameStarted)
+	}
+}
+
+func TestPlayBots(t *testing.T) {
+	te := newTestEngine()
+	g := te.newGame(2, Rules{Mode: ModePoints, Days: 30})
+	human := g.Players[0]
+	var bots []primitive.ObjectID
+	for _, name := range []string{"Robo", "Cog", "Bolt"} {
+		id, err := te.AddBot(g, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bots = append(bots, id)
+	}
+
+	for day := 0; day < 20; day++ {
+		// Claim a bot before the bots' turn, which Apply would take first
+		if day%2 == 0 {
+			if _, err := te.apply(g, human, claimRequest(bots[0])); err != nil && err != ErrNothingToClaim {
+				t.Fatal(err)
+			}
+		}
+		before := len(g.Events)
+		if !te.PlayBots(g) {
+			t.Fatalf("day %d: no bot acted", day)
+		}
+		if te.PlayBots(g) {
+			t.Fatalf("day %d: bots acted twice", day)
+		}
+
+		acted := make(map[primitive.ObjectID]bool)
+		for _, ev := range g.Events[before:] {
+			if ev.Type != EventClaim && ev.Type != EventGuess {
+				continue
+			}
+			if acted[ev.Actor] {
+				t.Errorf("day %d: %v acted twice", day, ev.Actor)
+			}
+			acted[ev.Actor] = true
+			// Bots only claim people who claimed them first today
+			if ev.Type == EventClaim && !g.player(ev.Target).Bot && (ev.Actor != bots[0] || ev.Target != human || day%2 != 0) {
+				t.Errorf("day %d: %v claimed %v out of the blue", day, ev.Actor, ev.Target)
+			}
+		}
+		for _, id := range bots {
+			if !acted[id] {
+				t.Errorf("day %d: bot %v didn't act", day, id)
+			}
+		}
+		te.nextDay()
+	}
+}
+
+func TestBotsFinishABingoGame(t *testing.T) {
+	te := newTestEngine()
+	g := &Game{ID: primitive.NewObjectID(), BoardSize: 3, Status: "active", CreatedAt: testStart, Rules: Rules{Mode: ModeBingo}}
+	for _, name := range []string{"Robo", "Cog", "Bolt"} {
+		if _, err := te.AddBot(g, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for day := 0; day < 100 && g.Status == "active"; day++ {
+		te.Advance(g)
+		te.nextDay()
+	}
+	if g.Status != "finished" || g.player(g.Winner) == nil {
+		t.Fatalf("after 100 days the game is %s, won by %v", g.Status, g.Winner)
+	}
+	if _, ok := completedPattern(g.player(g.Winner).Board, g.BoardSize, g.patterns()); !ok {
+		t.Error("the winner has no completed pattern")
+	}
+}
@@ -0,0 +1,243 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	FlagMutualClaims = "mutual_claims" // a pair keeps claiming each other within minutes
+	FlagRapidClaims  = "rapid_claims"  // claims too close together to have met in person
+	FlagHub          = "hub"           // one player is claimed by nearly everyone
+)
+
+var ErrThrottled = errors.New("claim throttled: too many mutual claims with that player")
+
+// ClaimRecord is one claim taken from a game's event history.
+type ClaimRecord struct {
+	Game   primitive.ObjectID
+	Seq    int
+	Actor  primitive.ObjectID
+	Target primitive.ObjectID
+	Time   time.Time
+}
+
+type Flag struct {
+	Kind   string   `json:"kind"`
+	Users  []string `json:"users"`
+	Games  []string `json:"games"`
+	Count  int      `json:"count"`
+	Detail string   `json:"detail"`
+}
+
+// Report returns the flags that involve the game. Rapid claims only show
+// up across games, so every game its players are in is analyzed.
+func Report(ctx context.Context, repo GameRepository, g Game) ([]Flag, error) {
+	games, err := repo.FindByPlayers(ctx, g.Players)
+	if err != nil {
+		return nil, err
+	}
+
+	flags := []Flag{}
+	for _, f := range DefaultAnalyzer().Analyze(ClaimHistory(games...)) {
+		for _, id := range f.Games {
+			if id == g.ID.Hex() {
+				flags = append(flags, f)
+				break
+			}
+		}
+	}
+	return flags, nil
+}
+
+// Analyzer looks for claim patterns that suggest friends farming each other.
+type Analyzer struct {
+	MutualWindow    time.Duration // max gap between A claiming B and B claiming A
+	MutualRepeats   int           // mutual claims per pair before flagging
+	MinClaimGap     time.Duration // claims by one player closer than this are suspicious
+	RapidRepeats    int           // rapid claims per player before flagging
+	HubShare        float64       // share of all claims landing on one player
+	HubMinClaimants int           // distinct claimants needed for a hub flag
+}
+
+func DefaultAnalyzer() Analyzer {
+	return Analyzer{
+		MutualWindow:    10 * time.Minute,
+		MutualRepeats:   2,
+		MinClaimGap:     2 * time.Minute,
+		RapidRepeats:    2,
+		HubShare:        0.5,
+		HubMinClaimants: 3,
+	}
+}
+
+// ClaimHistory collects the claims that still stand across games, oldest
+// first.
+func ClaimHistory(games ...Game) []ClaimRecord {
+	var claims []ClaimRecord
+	for _, g := range games {
+		for _, ev := range g.Events {
+			if ev.Type == EventClaim && !ev.Reverted {
+				claims = append(claims, ClaimRecord{Game: g.ID, Seq: ev.Seq, Actor: ev.Actor, Target: ev.Target, Time: ev.Time})
+			}
+		}
+	}
+	sort.SliceStable(claims, func(i, j int) bool { return claims[i].Time.Before(claims[j].Time) })
+	return claims
+}
+
+// Analyze flags suspicious patterns in a claim history.
+func (a Analyzer) Analyze(claims []ClaimRecord) []Flag {
+	var flags []Flag
+	flags = append(flags, a.mutualClaims(claims)...)
+	flags = append(flags, a.rapidClaims(claims)...)
+	flags = append(flags, a.hubs(claims)...)
+	return flags
+}
+
+func (a Analyzer) mutualClaims(claims []ClaimRecord) []Flag {
+	type pair [2]primitive.ObjectID
+	counts := make(map[pair]int)
+	games := make(map[pair]map[primitive.ObjectID]bool)
+	var order []pair
+
+	for i, c := range claims {
+		for _, back := range claims[i+1:] {
+			if back.Time.Sub(c.Time) > a.MutualWindow {
+				break
+			}
+			if back.Actor != c.Target || back.Target != c.Actor {
+				continue
+			}
+			p := pair{c.Actor, c.Target}
+			if c.Target.Hex() < c.Actor.Hex() {
+				p = pair{c.Target, c.Actor}
+			}
+			if counts[p] == 0 {
+				order = append(order, p)
+				games[p] = make(map[primitive.ObjectID]bool)
+			}
+			counts[p]++
+			games[p][c.Game] = true
+			games[p][back.Game] = true
+			break
+		}
+	}
+
+	var flags []Flag
+	for _, p := range order {
+		if counts[p] < a.MutualRepeats {
+			continue
+		}
+		flags = append(flags, Flag{
+			Kind:   FlagMutualClaims,
+			Users:  []string{p[0].Hex(), p[1].Hex()},
+			Games:  gameIDs(games[p]),
+			Count:  counts[p],
+			Detail: fmt.Sprintf("claimed each other within %s %d times", a.MutualWindow, counts[p]),
+		})
+	}
+	return flags
+}
+
+func (a Analyzer) rapidClaims(claims []ClaimRecord) []Flag {
+	last := make(map[primitive.ObjectID]ClaimRecord)
+	counts := make(map[primitive.ObjectID]int)
+	games := make(map[primitive.ObjectID]map[primitive.ObjectID]bool)
+	var order []primitive.ObjectID
+
+	for _, c := range claims {
+		prev, ok := last[c.Actor]
+		last[c.Actor] = c
+		if !ok || prev.Target == c.Target || c.Time.Sub(prev.Time) >= a.MinClaimGap {
+			continue
+		}
+		if counts[c.Actor] == 0 {
+			order = append(order, c.Actor)
+			games[c.Actor] = make(map[primitive.ObjectID]bool)
+		}
+		counts[c.Actor]++
+		games[c.Actor][prev.Game] = true
+		games[c.Actor][c.Game] = true
+	}
+
+	var flags []Flag
+	for _, user := range order {
+		if counts[user] < a.RapidRepeats {
+			continue
+		}
+		flags = append(flags, Flag{
+			Kind:   FlagRapidClaims,
+			Users:  []string{user.Hex()},
+			Games:  gameIDs(games[user]),
+			Count:  counts[user],
+			Detail: fmt.Sprintf("claimed different players less than %s apart %d times", a.MinClaimGap, counts[user]),
+		})
+	}
+	return flags
+}
+
+// hubs flags, per game, a player who receives a large share of the claims
+// from many different claimants, as if vouching for everyone.
+func (a Analyzer) hubs(claims []ClaimRecord) []Flag {
+	type key struct{ game, target primitive.ObjectID }
+	totals := make(map[primitive.ObjectID]int)
+	received := make(map[key]int)
+	claimants := make(map[key]map[primitive.ObjectID]bool)
+	var order []key
+
+	for _, c := range claims {
+		k := key{c.Game, c.Target}
+		totals[c.Game]++
+		if received[k] == 0 {
+			order = append(order, k)
+			claimants[k] = make(map[primitive.ObjectID]bool)
+		}
+		received[k]++
+		claimants[k][c.Actor] = true
+	}
+
+	var flags []Flag
+	for _, k := range order {
+		share := float64(received[k]) / float64(totals[k.game])
+		if share < a.HubShare || len(claimants[k]) < a.HubMinClaimants {
+			continue
+		}
+		flags = append(flags, Flag{
+			Kind:   FlagHub,
+			Users:  []string{k.target.Hex()},
+			Games:  []string{k.game.Hex()},
+			Count:  received[k],
+			Detail: fmt.Sprintf("received %.0f%% of claims from %d players", share*100, len(claimants[k])),
+		})
+	}
+	return flags
+}
+
+// throttled reports whether a claim would make the pair a repeat mutual
+// claimer in this game.
+func (e *Engine) throttled(g *Game, actor, target *Player) bool {
+	a := DefaultAnalyzer()
+	claims := append(ClaimHistory(*g), ClaimRecord{Game: g.ID, Actor: actor.User, Target: target.User, Time: e.now()})
+	for _, f := range a.mutualClaims(claims) {
+		if f.Users[0] == actor.User.Hex() && f.Users[1] == target.User.Hex() ||
+			f.Users[0] == target.User.Hex() && f.Users[1] == actor.User.Hex() {
+			return true
+		}
+	}
+	return false
+}
+
+func gameIDs(set map[primitive.ObjectID]bool) []string {
+	ids := make([]string, 0, len(set))
+	for id := range set {
+		ids = append(ids, id.Hex())
+	}
+	sort.Strings(ids)
+	return ids
+}
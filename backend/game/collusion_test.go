@@ -0,0 +1,156 @@
+package game
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestAnalyze(t *testing.T) {
+	a, b, c, d, h := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	game1, game2 := primitive.NewObjectID(), primitive.NewObjectID()
+	claim := func(game primitive.ObjectID, minutes int, actor, target primitive.ObjectID) ClaimRecord {
+		return ClaimRecord{Game: game, Actor: actor, Target: target, Time: testStart.Add(time.Duration(minutes) * time.Minute)}
+	}
+
+	tests := []struct {
+		name   string
+		claims []ClaimRecord
+		want   []string // kind and users of each flag
+	}{
+		{name: "nothing", claims: []ClaimRecord{
+			claim(game1, 0, a, b), claim(game1, 60, c, d),
+		}},
+		{name: "mutual claims", claims: []ClaimRecord{
+			claim(game1, 0, a, b), claim(game1, 5, b, a),
+			claim(game1, 1440, b, a), claim(game1, 1449, a, b),
+		}, want: []string{FlagMutualClaims + pairName(a, b)}},
+		{name: "one mutual claim", claims: []ClaimRecord{
+			claim(game1, 0, a, b), claim(game1, 5, b, a),
+		}},
+		{name: "claimed back too late", claims: []ClaimRecord{
+			claim(game1, 0, a, b), claim(game1, 20, b, a),
+			claim(game1, 1440, a, b), claim(game1, 1460, b, a),
+		}},
+		{name: "rapid claims across games", claims: []ClaimRecord{
+			claim(game1, 0, a, b), claim(game2, 1, a, c), claim(game1, 2, a, d),
+		}, want: []string{FlagRapidClaims + a.Hex()}},
+		{name: "same player twice is not rapid", claims: []ClaimRecord{
+			claim(game1, 0, a, b), claim(game2, 1, a, b), claim(game2, 2, a, b),
+		}},
+		{name: "hub", claims: []ClaimRecord{
+			claim(game1, 0, a, h), claim(game1, 60, b, h), claim(game1, 120, c, h), claim(game1, 180, d, a),
+		}, want: []string{FlagHub + h.Hex()}},
+		{name: "popular with too few claimants", claims: []ClaimRecord{
+			claim(game1, 0, a, h), claim(game1, 1440, a, h), claim(game1, 2880, b, h),
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, f := range DefaultAnalyzer().Analyze(tt.claims) {
+				if f.Kind == FlagMutualClaims {
+					got = append(got, f.Kind+pairName(hexID(t, f.Users[0]), hexID(t, f.Users[1])))
+				} else {
+					got = append(got, f.Kind+f.Users[0])
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("flags = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// pairName names a pair the same way round whichever order it is given in.
+func pairName(x, y primitive.ObjectID) string {
+	if y.Hex() < x.Hex() {
+		x, y = y, x
+	}
+	return x.Hex() + y.Hex()
+}
+
+func hexID(t *testing.T, s string) primitive.ObjectID {
+	id, err := primitive.ObjectIDFromHex(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func TestClaimHistory(t *testing.T) {
+	a, b := primitive.NewObjectID(), primitive.NewObjectID()
+	late := Game{ID: primitive.NewObjectID(), Events: []Event{
+		{Seq: 1, Type: EventClaim, Actor: a, Target: b, Time: testStart.Add(time.Hour)},
+		{Seq: 2, Type: EventGuess, Actor: b, Target: a, Time: testStart.Add(2 * time.Hour)},
+	}}
+	early := Game{ID: primitive.NewObjectID(), Events: []Event{
+		{Seq: 1, Type: EventClaim, Actor: b, Target: a, Time: testStart},
+		{Seq: 2, Type: EventClaim, Actor: a, Target: b, Time: testStart.Add(time.Minute), Reverted: true},
+	}}
+
+	claims := ClaimHistory(late, early)
+	if len(claims) != 2 || claims[0].Game != early.ID || claims[1].Game != late.ID {
+		t.Errorf("claims = %+v, want the standing claims oldest first", claims)
+	}
+}
+
+type playerGames struct {
+	GameRepository
+	games []Game
+}
+
+func (r playerGames) FindByPlayers(ctx context.Context, playerIDs []primitive.ObjectID) ([]Game, error) {
+	return r.games, nil
+}
+
+func TestReport(t *testing.T) {
+	a, b, c := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	farmed := Game{ID: primitive.NewObjectID(), Players: []primitive.ObjectID{a, b}}
+	for day := 0; day < 2; day++ {
+		at := testStart.Add(time.Duration(day) * 24 * time.Hour)
+		farmed.Events = append(farmed.Events,
+			Event{Seq: 2*day + 1, Type: EventClaim, Actor: a, Target: b, Time: at},
+			Event{Seq: 2*day + 2, Type: EventClaim, Actor: b, Target: a, Time: at.Add(time.Minute)},
+		)
+	}
+	other := Game{ID: primitive.NewObjectID(), Players: []primitive.ObjectID{a, c}}
+	repo := playerGames{games: []Game{farmed, other}}
+
+	flags, err := Report(context.Background(), repo, farmed)
+	if err != nil || len(flags) != 1 || flags[0].Kind != FlagMutualClaims {
+		t.Errorf("report = %+v, %v, want the mutual claims", flags, err)
+	}
+	flags, err = Report(context.Background(), repo, other)
+	if err != nil || flags == nil || len(flags) != 0 {
+		t.Errorf("report = %#v, %v, want an empty list for a game with no flags", flags, err)
+	}
+}
+
+func TestThrottle(t *testing.T) {
+	te := newTestEngine()
+	g := te.newGame(3, Rules{Mode: ModeBingo, Throttle: true})
+	a, b := g.Players[1], g.Players[2]
+
+	for day := 0; day < 2; day++ {
+		if _, err := te.Apply(g, a, claimRequest(b)); err != nil {
+			t.Fatalf("day %d: %v", day, err)
+		}
+		_, err := te.Apply(g, b, claimRequest(a))
+		if day == 0 && err != nil {
+			t.Fatalf("first claim back: %v", err)
+		}
+		if day == 1 && err != ErrThrottled {
+			t.Fatalf("second claim back: err = %v, want %v", err, ErrThrottled)
+		}
+		te.nextDay()
+	}
+
+	// Claiming someone else is fine
+	if _, err := te.Apply(g, b, claimRequest(g.Players[0])); err != nil {
+		t.Errorf("claim of another player: %v", err)
+	}
+}
@@ -0,0 +1,48 @@
+package game
+
+// tickCooties runs the daily Cooties effects for every day up to and
+// including last that hasn't been processed yet. It reports whether
+// anything happened.
+func (e *Engine) tickCooties(g *Game, last int) bool {
+	rules := g.rules()
+	changed := false
+	for ; g.Ticked < last; g.Ticked++ {
+		day := g.Ticked + 1
+		holder := g.cootiesHolder()
+		if holder == nil {
+			continue
+		}
+
+		held := day - g.CootiesSince
+		if rules.TransferDays > 0 && held >= rules.TransferDays && len(g.PlayerStates) > 1 {
+			next := holder
+			for next == holder {
+				next = &g.PlayerStates[e.rng.Intn(len(g.PlayerStates))]
+			}
+			holder.Cooties = false
+			next.Cooties = true
+			g.CootiesSince = day
+			e.record(g, Event{Type: EventCootiesMoved, Actor: holder.User, Target: next.User})
+			changed = true
+			continue
+		}
+
+		if rules.DecayDays > 0 && held > 0 && held%rules.DecayDays == 0 {
+			if i, ok := e.loseTile(holder); ok {
+				holder.Board[i].Decayed = true
+				e.record(g, Event{Type: EventCootiesDecay, Actor: holder.User, Tiles: []int{i}})
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+func (g *Game) cootiesHolder() *Player {
+	for i := range g.PlayerStates {
+		if g.PlayerStates[i].Cooties {
+			return &g.PlayerStates[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package game
+
+import "testing"
+
+func TestDecayHiddenUntilFinished(t *testing.T) {
+	te := newTestEngine()
+	g := te.newGame(3, Rules{Mode: ModePoints, Days: 7, TilePoints: 1, DecayDays: 1})
+	holder, other := &g.PlayerStates[0], g.PlayerStates[1].User
+	holder.claimTiles(other, 2)
+
+	te.nextDay()
+	if !te.Advance(g) || holder.ClaimedCount != 1 {
+		t.Fatalf("holder has %d tiles after a day, want 1 lost to decay", holder.ClaimedCount)
+	}
+
+	if got := g.ViewFor(holder.User).PlayerStates[0].ClaimedCount; got != 1 {
+		t.Errorf("holder sees %d claimed tiles, want 1", got)
+	}
+	if got := g.ViewFor(other).PlayerStates[0].ClaimedCount; got != 2 {
+		t.Errorf("others see %d claimed tiles, want 2", got)
+	}
+	if got := standing(g, holder).Tiles; got != 2 {
+		t.Errorf("standings show %d tiles, want 2", got)
+	}
+
+	// Reclaiming takes a tile nobody saw go first
+	holder.claimTiles(g.PlayerStates[2].User, 1)
+	if got := g.ViewFor(other).PlayerStates[0].ClaimedCount; got != 3 {
+		t.Errorf("others see %d claimed tiles after a claim, want 3", got)
+	}
+
+	if err := te.ForceFinish(g, holder.User); err != nil {
+		t.Fatal(err)
+	}
+	if got := g.ViewFor(other).PlayerStates[0].ClaimedCount; got != 2 {
+		t.Errorf("others see %d claimed tiles once finished, want 2", got)
+	}
+	if got := standing(g, holder).Tiles; got != 2 {
+		t.Errorf("final standings show %d tiles, want 2", got)
+	}
+}
+
+func standing(g *Game, p *Player) Standing {
+	for _, s := range g.Standings() {
+		if s.UserID == p.User.Hex() {
+			return s
+		}
+	}
+	return Standing{}
+}
@@ -0,0 +1,168 @@
+package game
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	DisputeOpen     = "open"
+	DisputeUpheld   = "upheld"
+	DisputeReverted = "reverted"
+)
+
+var (
+	ErrNoSuchDispute = errors.New("dispute not found")
+	ErrNotDisputable = errors.New("that event can't be disputed")
+	ErrDisputeClosed = errors.New("dispute is already resolved")
+	ErrCannotVote    = errors.New("you can't vote on this dispute")
+	ErrAlreadyVoted  = errors.New("already voted on this dispute")
+	ErrDisputeParty  = errors.New("the host can't decide a dispute they are part of")
+)
+
+// Dispute is a challenge to a claim by the player who was claimed. The
+// claimed tiles stay frozen until the host or a majority of the other
+// players decides.
+type Dispute struct {
+	ID         int                `bson:"id"`
+	Event      int                `bson:"event"` // seq of the disputed claim
+	Claimant   primitive.ObjectID `bson:"claimant"`
+	Disputer   primitive.ObjectID `bson:"disputer"`
+	Reason     string             `bson:"reason"`
+	Status     string             `bson:"status"`
+	Votes      map[string]bool    `bson:"votes"` // user ID hex to uphold
+	CreatedAt  time.Time          `bson:"createdAt"`
+	ResolvedAt time.Time          `bson:"resolvedAt,omitempty"`
+}
+
+// OpenDispute lets the target of a claim challenge it.
+func (e *Engine) OpenDispute(g *Game, userID primitive.ObjectID, seq int, reason string) (Dispute, error) {
+	ev := g.event(seq)
+	if ev == nil || ev.Type != EventClaim || ev.Reverted || ev.Target != userID {
+		return Dispute{}, ErrNotDisputable
+	}
+	for _, d := range g.Disputes {
+		if d.Event == seq {
+			return Dispute{}, ErrNotDisputable
+		}
+	}
+
+	if claimant := g.player(ev.Actor); claimant != nil {
+		for _, i := range ev.Tiles {
+			if i < len(claimant.Board) && claimant.Board[i].Claimed {
+				claimant.Board[i].Frozen = true
+			}
+		}
+	}
+
+	d := Dispute{
+		ID:        len(g.Disputes) + 1,
+		Event:     seq,
+		Claimant:  ev.Actor,
+		Disputer:  userID,
+		Reason:    reason,
+		Status:    DisputeOpen,
+		Votes:     make(map[string]bool),
+		CreatedAt: e.now(),
+	}
+	g.Disputes = append(g.Disputes, d)
+	e.record(g, Event{Type: EventDisputeOpened, Actor: userID, Target: ev.Actor, Dispute: d.ID})
+	return d, nil
+}
+
+// VoteDispute records a player's vote and resolves the dispute once a
+// majority of the players who aren't party to it agree.
+func (e *Engine) VoteDispute(g *Game, userID primitive.ObjectID, id int, uphold bool) (Dispute, error) {
+	d, err := g.openDispute(id)
+	if err != nil {
+		return Dispute{}, err
+	}
+	if p := g.player(userID); p == nil || !canVote(*p, d) {
+		return Dispute{}, ErrCannotVote
+	}
+	if _, ok := d.Votes[userID.Hex()]; ok {
+		return Dispute{}, ErrAlreadyVoted
+	}
+	d.Votes[userID.Hex()] = uphold
+
+	voters := 0
+	for _, p := range g.PlayerStates {
+		if canVote(p, d) {
+			voters++
+		}
+	}
+	upholds := 0
+	for _, v := range d.Votes {
+		if v {
+			upholds++
+		}
+	}
+	switch {
+	case upholds*2 > voters:
+		e.resolve(g, d, true)
+	case (len(d.Votes)-upholds)*2 > voters:
+		e.resolve(g, d, false)
+	}
+	return *d, nil
+}
+
+// ResolveDispute lets the host decide a dispute directly, unless it is
+// over their own claim or they raised it. Those go to the vote.
+func (e *Engine) ResolveDispute(g *Game, userID primitive.ObjectID, id int, uphold bool) (Dispute, error) {
+	if userID != g.Host {
+		return Dispute{}, ErrNotHost
+	}
+	d, err := g.openDispute(id)
+	if err != nil {
+		return Dispute{}, err
+	}
+	if userID == d.Claimant || userID == d.Disputer {
+		return Dispute{}, ErrDisputeParty
+	}
+	e.resolve(g, d, uphold)
+	return *d, nil
+}
+
+// resolve unfreezes an upheld claim, which may complete a pattern, or
+// reverts the claim together with everything it caused.
+func (e *Engine) resolve(g *Game, d *Dispute, uphold bool) {
+	d.ResolvedAt = e.now()
+	seq := e.record(g, Event{Type: EventDisputeResolved, Actor: d.Disputer, Target: d.Claimant, Dispute: d.ID})
+
+	if !uphold {
+		d.Status = DisputeReverted
+		e.revert(g, d.Event)
+		return
+	}
+
+	d.Status = DisputeUpheld
+	claimant := g.player(d.Claimant)
+	if claimant == nil {
+		return
+	}
+	for _, i := range g.event(d.Event).Tiles {
+		if i < len(claimant.Board) {
+			claimant.Board[i].Frozen = false
+		}
+	}
+	e.checkWin(g, claimant, seq)
+}
+
+// canVote reports whether p has a say in d. Bots and deleted players
+// can't vote, so they don't count towards the majority either.
+func canVote(p Player, d *Dispute) bool {
+	return !p.Bot && !p.Deleted && p.User != d.Claimant && p.User != d.Disputer
+}
+
+func (g *Game) openDispute(id int) (*Dispute, error) {
+	if id < 1 || id > len(g.Disputes) {
+		return nil, ErrNoSuchDispute
+	}
+	d := &g.Disputes[id-1]
+	if d.Status != DisputeOpen {
+		return nil, ErrDisputeClosed
+	}
+	return d, nil
+}
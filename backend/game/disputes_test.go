@@ -0,0 +1,85 @@
+package game
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// disputedClaim has claimant claim disputer and disputer dispute it.
+func disputedClaim(t *testing.T, te *testEngine, g *Game, claimant, disputer primitive.ObjectID) Dispute {
+	t.Helper()
+	result, err := te.Apply(g, claimant, claimRequest(disputer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := te.OpenDispute(g, disputer, result.Event, "we never met")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestResolveDispute(t *testing.T) {
+	tests := []struct {
+		name               string
+		claimant, disputer int // player indexes, the host is 0
+		decider            int
+		wantErr            error
+	}{
+		{name: "host decides", claimant: 1, disputer: 2, decider: 0},
+		{name: "not the host", claimant: 1, disputer: 2, decider: 3, wantErr: ErrNotHost},
+		{name: "host's own claim", claimant: 0, disputer: 1, decider: 0, wantErr: ErrDisputeParty},
+		{name: "host's own dispute", claimant: 1, disputer: 0, decider: 0, wantErr: ErrDisputeParty},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			te := newTestEngine()
+			g := te.newGame(4, Rules{Mode: ModePoints, Days: 7})
+			g.Host = g.Players[0]
+			d := disputedClaim(t, te, g, g.Players[tt.claimant], g.Players[tt.disputer])
+
+			_, err := te.ResolveDispute(g, g.Players[tt.decider], d.ID, false)
+			if err != tt.wantErr {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			wantStatus := DisputeReverted
+			if tt.wantErr != nil {
+				wantStatus = DisputeOpen
+			}
+			if g.Disputes[0].Status != wantStatus {
+				t.Errorf("status = %s, want %s", g.Disputes[0].Status, wantStatus)
+			}
+		})
+	}
+}
+
+func TestVoteDispute(t *testing.T) {
+	te := newTestEngine()
+	g := te.newGame(6, Rules{Mode: ModePoints, Days: 7})
+	g.PlayerStates[4].Bot = true
+	g.PlayerStates[5].Deleted = true
+	d := disputedClaim(t, te, g, g.Players[0], g.Players[1])
+
+	for _, voter := range []int{0, 1, 4, 5} {
+		if _, err := te.VoteDispute(g, g.Players[voter], d.ID, true); err != ErrCannotVote {
+			t.Errorf("player %d voting: err = %v, want %v", voter, err, ErrCannotVote)
+		}
+	}
+
+	// Players 2 and 3 are the only voters, so it takes both of them
+	d, err := te.VoteDispute(g, g.Players[2], d.ID, false)
+	if err != nil || d.Status != DisputeOpen {
+		t.Fatalf("after one vote: status %s, err %v, want still open", d.Status, err)
+	}
+	if _, err := te.VoteDispute(g, g.Players[2], d.ID, false); err != ErrAlreadyVoted {
+		t.Errorf("voting twice: err = %v, want %v", err, ErrAlreadyVoted)
+	}
+	d, err = te.VoteDispute(g, g.Players[3], d.ID, false)
+	if err != nil || d.Status != DisputeReverted {
+		t.Fatalf("after two votes: status %s, err %v, want reverted", d.Status, err)
+	}
+	if g.PlayerStates[0].ClaimedCount != 0 {
+		t.Errorf("claimant kept %d tiles of the reverted claim", g.PlayerStates[0].ClaimedCount)
+	}
+}
@@ -0,0 +1,328 @@
+package game
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	ActionClaim = "claim"
+	ActionGuess = "guess"
+	ActionItem  = "item"
+)
+
+var (
+	ErrGameNotActive  = errors.New("game is not active")
+	ErrGameStarted    = errors.New("game already started")
+	ErrNotInGame      = errors.New("player is not in this game")
+	ErrAlreadyActed   = errors.New("already acted today")
+	ErrInvalidTarget  = errors.New("invalid target")
+	ErrUnknownAction  = errors.New("unknown action")
+	ErrNothingToClaim = errors.New("no unclaimed tile for that player")
+	ErrUnknownItem    = errors.New("unknown item")
+	ErrNotHost        = errors.New("only the host can do that")
+	ErrNoSuchItem     = errors.New("item not in inventory")
+)
+
+type ActionResult struct {
+	Action     string   `json:"action"`
+	Correct    bool     `json:"correct,omitempty"` // guesses only
+	Gained     []int    `json:"gained,omitempty"`  // tile indexes claimed
+	Lost       []int    `json:"lost,omitempty"`    // tile indexes unclaimed
+	Item       string   `json:"item,omitempty"`    // item used
+	HasCooties *bool    `json:"hasCooties,omitempty"`
+	Earned     []string `json:"earned,omitempty"` // items awarded
+	Event      int      `json:"event"`            // seq of the recorded event
+	Won        bool     `json:"won"`
+	WinPattern Pattern  `json:"winPattern,omitempty"`
+}
+
+// Engine applies the game rules to a Game in memory. It does no I/O, so
+// callers load the game, apply actions and save the result.
+type Engine struct {
+	rng *rand.Rand
+	now func() time.Time
+}
+
+func NewEngine(rng *rand.Rand) *Engine {
+	return &Engine{rng: rng, now: time.Now}
+}
+
+// SetClock replaces the wall clock, for running games on simulated time.
+func (e *Engine) SetClock(now func() time.Time) {
+	e.now = now
+}
+
+// Deal gives every player a fresh shuffled board and hands Cooties to one
+// of them at random.
+func (e *Engine) Deal(g *Game) {
+	previous := make(map[primitive.ObjectID]Player)
+	for _, p := range g.PlayerStates {
+		previous[p.User] = p
+	}
+
+	states := make([]Player, len(g.Players))
+	for i, userID := range g.Players {
+		states[i] = Player{
+			ID:         primitive.NewObjectID(),
+			PlayerName: previous[userID].PlayerName,
+			User:       userID,
+			Bot:        previous[userID].Bot,
+			Board:      e.newBoard(g, userID),
+		}
+	}
+	if len(states) > 0 {
+		states[e.rng.Intn(len(states))].Cooties = true
+	}
+	g.CootiesSince = g.Day(e.now())
+	g.Ticked = g.CootiesSince
+	g.PlayerStates = states
+}
+
+// newBoard fills a board with the other players' IDs, repeating them in
+// shuffled rounds when there are more tiles than friends.
+func (e *Engine) newBoard(g *Game, userID primitive.ObjectID) []Tile {
+	var friends []primitive.ObjectID
+	for _, id := range g.Players {
+		if id != userID {
+			friends = append(friends, id)
+		}
+	}
+
+	total := g.BoardSize * g.BoardSize
+	board := make([]Tile, 0, total)
+	for len(board) < total && len(friends) > 0 {
+		e.rng.Shuffle(len(friends), func(i, j int) { friends[i], friends[j] = friends[j], friends[i] })
+		for _, id := range friends {
+			if len(board) == total {
+				break
+			}
+			board = append(board, Tile{FriendID: id})
+		}
+	}
+	for len(board) < total {
+		board = append(board, Tile{})
+	}
+
+	if g.FreeCenter && g.BoardSize%2 == 1 {
+		center := total / 2
+		board[center] = Tile{Claimed: true, Free: true}
+	}
+	return board
+}
+
+// Apply brings the game up to date, then performs one player action and
+// checks for a win.
+func (e *Engine) Apply(g *Game, userID primitive.ObjectID, req ActionRequest) (ActionResult, error) {
+	e.Advance(g)
+	return e.apply(g, userID, req)
+}
+
+func (e *Engine) apply(g *Game, userID primitive.ObjectID, req ActionRequest) (ActionResult, error) {
+	if g.Status != "active" {
+		return ActionResult{}, ErrGameNotActive
+	}
+
+	actor := g.player(userID)
+	if actor == nil {
+		return ActionResult{}, ErrNotInGame
+	}
+
+	now := e.now()
+	if !req.free() && !actor.LastAction.IsZero() && g.Day(actor.LastAction) == g.Day(now) {
+		return ActionResult{}, ErrAlreadyActed
+	}
+
+	var result ActionResult
+	var err error
+	switch req.Action {
+	case ActionClaim:
+		var target *Player
+		if target, err = g.target(userID, req.TargetID); err == nil {
+			result, err = e.claim(g, actor, target, 1, "")
+		}
+	case ActionGuess:
+		var target *Player
+		if target, err = g.target(userID, req.TargetID); err == nil {
+			result = e.guess(g, actor, target)
+		}
+	case ActionItem:
+		result, err = e.useItem(g, actor, req)
+	default:
+		err = ErrUnknownAction
+	}
+	if err != nil {
+		return ActionResult{}, err
+	}
+	if req.free() {
+		return result, nil
+	}
+
+	if !actor.LastAction.IsZero() && g.Day(actor.LastAction) == g.Day(now)-1 {
+		actor.Streak++
+	} else {
+		actor.Streak = 1
+	}
+	actor.LastAction = now
+	result.Earned = e.awardItems(g, actor, result.Event)
+
+	if p, ok := e.checkWin(g, actor, result.Event); ok {
+		result.Won = true
+		result.WinPattern = p
+	}
+	return result, nil
+}
+
+// checkWin finishes a bingo game if the player has completed a pattern.
+func (e *Engine) checkWin(g *Game, p *Player, cause int) (Pattern, bool) {
+	if g.Status != "active" || g.rules().Mode != ModeBingo {
+		return "", false
+	}
+	pattern, ok := completedPattern(p.Board, g.BoardSize, g.patterns())
+	if !ok {
+		return "", false
+	}
+	g.Status = "finished"
+	g.Winner = p.User
+	g.WinPattern = pattern
+	e.record(g, Event{Type: EventWin, Actor: p.User, Pattern: pattern, Cause: cause})
+	return pattern, true
+}
+
+// claim marks up to n of the actor's unclaimed tiles for the target.
+// Claiming while holding Cooties passes them on, unless the target is
+// wearing a Mask today.
+func (e *Engine) claim(g *Game, actor, target *Player, n int, item string) (ActionResult, error) {
+	if g.rules().Throttle && e.throttled(g, actor, target) {
+		return ActionResult{}, ErrThrottled
+	}
+
+	result := ActionResult{Action: ActionClaim, Gained: actor.claimTiles(target.User, n)}
+	if len(result.Gained) == 0 {
+		return ActionResult{}, ErrNothingToClaim
+	}
+
+	result.Event = e.record(g, Event{Type: EventClaim, Actor: actor.User, Target: target.User, Tiles: result.Gained, Item: item})
+	if actor.Cooties && !g.activeToday(target.MaskedOn, e.now()) {
+		actor.Cooties = false
+		target.Cooties = true
+		e.record(g, Event{Type: EventCootiesTransfer, Actor: actor.User, Target: target.User, Cause: result.Event, Since: g.CootiesSince})
+		g.CootiesSince = g.Day(e.now())
+	}
+	return result, nil
+}
+
+// guess checks whether the target holds Cooties. A correct guess claims up
+// to two of the target's tiles, a wrong one loses a random claimed tile
+// unless the actor raised a Shield today.
+func (e *Engine) guess(g *Game, actor, target *Player) ActionResult {
+	result := ActionResult{Action: ActionGuess, Correct: target.Cooties}
+	if target.Cooties {
+		actor.CorrectGuesses++
+		result.Gained = actor.claimTiles(target.User, 2)
+	}
+
+	result.Event = e.record(g, Event{Type: EventGuess, Actor: actor.User, Target: target.User, Tiles: result.Gained, Correct: result.Correct})
+	if !result.Correct && !g.activeToday(actor.ShieldedOn, e.now()) {
+		if i, ok := e.loseTile(actor); ok {
+			result.Lost = []int{i}
+			e.record(g, Event{Type: EventTileLost, Actor: actor.User, Tiles: result.Lost, Cause: result.Event})
+		}
+	}
+	return result
+}
+
+// claimTiles claims up to n of the player's unclaimed tiles for friend.
+// Tiles lost to Cooties decay come last, since everyone else still sees
+// them as claimed.
+func (p *Player) claimTiles(friend primitive.ObjectID, n int) []int {
+	var fresh, decayed []int
+	for i, t := range p.Board {
+		switch {
+		case t.Claimed || t.FriendID != friend:
+		case t.Decayed:
+			decayed = append(decayed, i)
+		default:
+			fresh = append(fresh, i)
+		}
+	}
+	tiles := append(fresh, decayed...)
+	if len(tiles) > n {
+		tiles = tiles[:n]
+	}
+	for _, i := range tiles {
+		p.Board[i].Claimed = true
+		p.Board[i].Decayed = false
+		p.ClaimedCount++
+	}
+	return tiles
+}
+
+// loseTile unclaims a random claimed tile, never the free center or a
+// tile frozen by a dispute.
+func (e *Engine) loseTile(p *Player) (int, bool) {
+	var claimed []int
+	for i, t := range p.Board {
+		if t.Claimed && !t.Free && !t.Frozen {
+			claimed = append(claimed, i)
+		}
+	}
+	if len(claimed) == 0 {
+		return 0, false
+	}
+	i := claimed[e.rng.Intn(len(claimed))]
+	p.Board[i].Claimed = false
+	p.ClaimedCount--
+	return i, true
+}
+
+// Started reports whether any player has taken an action yet.
+func (g *Game) Started() bool {
+	for _, p := range g.PlayerStates {
+		if !p.LastAction.IsZero() {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Game) player(userID primitive.ObjectID) *Player {
+	for i := range g.PlayerStates {
+		if g.PlayerStates[i].User == userID {
+			return &g.PlayerStates[i]
+		}
+	}
+	return nil
+}
+
+func (g *Game) target(userID primitive.ObjectID, targetID string) (*Player, error) {
+	id, err := primitive.ObjectIDFromHex(targetID)
+	if err != nil || id == userID {
+		return nil, ErrInvalidTarget
+	}
+	target := g.player(id)
+	if target == nil {
+		return nil, ErrInvalidTarget
+	}
+	return target, nil
+}
+
+func (g *Game) patterns() []Pattern {
+	if len(g.Patterns) == 0 {
+		return DefaultPatterns
+	}
+	return g.Patterns
+}
+
+// activeToday reports whether an item activated at t still applies at now.
+func (g *Game) activeToday(t, now time.Time) bool {
+	return !t.IsZero() && g.Day(t) == g.Day(now)
+}
+
+// Day is the number of whole days since the game was created.
+func (g *Game) Day(t time.Time) int {
+	return int(t.Sub(g.CreatedAt) / (24 * time.Hour))
+}
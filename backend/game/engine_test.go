@@ -0,0 +1,233 @@
+package game
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var testStart = time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+
+// testEngine runs games on a clock the test moves by hand.
+type testEngine struct {
+	*Engine
+	now time.Time
+}
+
+func newTestEngine() *testEngine {
+	te := &testEngine{Engine: NewEngine(rand.New(rand.NewSource(1))), now: testStart}
+	te.SetClock(func() time.Time { return te.now })
+	return te
+}
+
+// nextDay moves the clock on by a day.
+func (te *testEngine) nextDay() {
+	te.now = te.now.Add(24 * time.Hour)
+}
+
+// newGame deals an active 3x3 game between n players and hands Cooties
+// to the first.
+func (te *testEngine) newGame(n int, rules Rules) *Game {
+	g := &Game{ID: primitive.NewObjectID(), BoardSize: 3, Status: "active", CreatedAt: testStart, Rules: rules}
+	for i := 0; i < n; i++ {
+		g.Players = append(g.Players, primitive.NewObjectID())
+	}
+	te.Deal(g)
+	for i := range g.PlayerStates {
+		g.PlayerStates[i].Cooties = i == 0
+	}
+	return g
+}
+
+func claimRequest(target primitive.ObjectID) ActionRequest {
+	return ActionRequest{Action: ActionClaim, TargetID: target.Hex()}
+}
+
+// fillBoard gives p a board of friend's tiles with the given ones claimed.
+func fillBoard(g *Game, p *Player, friend primitive.ObjectID, claimed ...int) {
+	p.Board = testBoard(g.BoardSize, claimed...)
+	for i := range p.Board {
+		p.Board[i].FriendID = friend
+	}
+	p.ClaimedCount = len(claimed)
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(te *testEngine, g *Game)
+		target  int // player index, -1 for a malformed ID
+		action  string
+		wantErr error
+	}{
+		{name: "claim", target: 2, action: ActionClaim},
+		{name: "guess", target: 2, action: ActionGuess},
+		{name: "claim yourself", target: 1, action: ActionClaim, wantErr: ErrInvalidTarget},
+		{name: "malformed target", target: -1, action: ActionClaim, wantErr: ErrInvalidTarget},
+		{name: "unknown action", target: 2, action: "wave", wantErr: ErrUnknownAction},
+		{name: "finished game", target: 2, action: ActionClaim, wantErr: ErrGameNotActive, setup: func(te *testEngine, g *Game) {
+			g.Status = "finished"
+		}},
+		{name: "acted today", target: 2, action: ActionClaim, wantErr: ErrAlreadyActed, setup: func(te *testEngine, g *Game) {
+			g.PlayerStates[1].LastAction = te.now.Add(-time.Hour)
+		}},
+		{name: "acted yesterday", target: 2, action: ActionClaim, setup: func(te *testEngine, g *Game) {
+			te.nextDay()
+			g.PlayerStates[1].LastAction = te.now.Add(-24 * time.Hour)
+		}},
+		{name: "nothing left to claim", target: 2, action: ActionClaim, wantErr: ErrNothingToClaim, setup: func(te *testEngine, g *Game) {
+			fillBoard(g, &g.PlayerStates[1], g.Players[2], 0, 1, 2, 3, 4, 5, 6, 7, 8)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			te := newTestEngine()
+			g := te.newGame(3, Rules{Mode: ModeBingo})
+			if tt.setup != nil {
+				tt.setup(te, g)
+			}
+			req := ActionRequest{Action: tt.action, TargetID: "nope"}
+			if tt.target >= 0 {
+				req.TargetID = g.Players[tt.target].Hex()
+			}
+			before := len(g.Events)
+
+			result, err := te.Apply(g, g.Players[1], req)
+			if err != tt.wantErr {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if len(g.Events) != before {
+					t.Errorf("a refused action recorded %+v", g.Events[before:])
+				}
+				return
+			}
+			if result.Action != tt.action || result.Event != before+1 || g.Events[before].Type != tt.action {
+				t.Errorf("result = %+v, events %+v", result, g.Events[before:])
+			}
+			if !g.PlayerStates[1].LastAction.Equal(te.now) {
+				t.Errorf("last action = %v, want now", g.PlayerStates[1].LastAction)
+			}
+		})
+	}
+}
+
+func TestApplyNotInGame(t *testing.T) {
+	te := newTestEngine()
+	g := te.newGame(3, Rules{Mode: ModeBingo})
+	if _, err := te.Apply(g, primitive.NewObjectID(), claimRequest(g.Players[1])); err != ErrNotInGame {
+		t.Errorf("err = %v, want %v", err, ErrNotInGame)
+	}
+}
+
+func TestApplyClaimPassesCooties(t *testing.T) {
+	te := newTestEngine()
+	g := te.newGame(3, Rules{Mode: ModeBingo})
+	holder, target := g.Players[0], g.Players[1]
+
+	result, err := te.Apply(g, holder, claimRequest(target))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Gained) != 1 || g.PlayerStates[0].ClaimedCount != 1 {
+		t.Errorf("gained %v, claimed count %d, want one tile", result.Gained, g.PlayerStates[0].ClaimedCount)
+	}
+	if g.PlayerStates[0].Cooties || !g.PlayerStates[1].Cooties {
+		t.Errorf("Cooties stayed with the claimer")
+	}
+	transfer := g.Events[len(g.Events)-1]
+	if transfer.Type != EventCootiesTransfer || transfer.Actor != holder || transfer.Target != target || transfer.Cause != result.Event {
+		t.Errorf("last event = %+v, want a transfer caused by the claim", transfer)
+	}
+}
+
+func TestApplyGuess(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     int
+		wantGained int
+		wantLost   int
+	}{
+		{name: "correct", target: 0, wantGained: 2},
+		{name: "wrong", target: 2, wantLost: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			te := newTestEngine()
+			g := te.newGame(3, Rules{Mode: ModeBingo})
+			guesser := &g.PlayerStates[1]
+			fillBoard(g, guesser, g.Players[tt.target], 8)
+
+			result, err := te.Apply(g, guesser.User, ActionRequest{Action: ActionGuess, TargetID: g.Players[tt.target].Hex()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Correct != (tt.wantGained > 0) || len(result.Gained) != tt.wantGained || len(result.Lost) != tt.wantLost {
+				t.Errorf("result = %+v", result)
+			}
+			if want := 1 + tt.wantGained - tt.wantLost; guesser.ClaimedCount != want {
+				t.Errorf("claimed count = %d, want %d", guesser.ClaimedCount, want)
+			}
+		})
+	}
+}
+
+func TestApplyWin(t *testing.T) {
+	tests := []struct {
+		name       string
+		patterns   []Pattern
+		freeCenter bool
+		claimed    []int
+		claims     int // the one tile the winning claim can take
+		mode       string
+		want       Pattern
+	}{
+		{name: "line", claimed: []int{0, 1}, claims: 2, want: PatternLine},
+		{name: "no line yet", claimed: []int{0, 4}, claims: 2},
+		{name: "free center", freeCenter: true, claimed: []int{3}, claims: 5, want: PatternLine},
+		{name: "no free center", claimed: []int{3}, claims: 5},
+		{name: "corners", patterns: []Pattern{PatternCorners}, claimed: []int{0, 2, 6}, claims: 8, want: PatternCorners},
+		{name: "line without the line pattern", patterns: []Pattern{PatternCorners}, claimed: []int{0, 1}, claims: 2},
+		{name: "points games have no bingo", mode: ModePoints, claimed: []int{0, 1}, claims: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			te := newTestEngine()
+			rules := Rules{Mode: ModeBingo}
+			if tt.mode != "" {
+				rules = Rules{Mode: tt.mode, Days: 7}
+			}
+			g := te.newGame(3, rules)
+			g.Patterns = tt.patterns
+			g.FreeCenter = tt.freeCenter
+			winner := &g.PlayerStates[1]
+			fillBoard(g, winner, g.Players[0], tt.claimed...)
+			winner.Board[tt.claims].FriendID = g.Players[2]
+			if tt.freeCenter {
+				winner.Board[4] = Tile{Claimed: true, Free: true}
+			}
+
+			result, err := te.Apply(g, winner.User, claimRequest(g.Players[2]))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Won != (tt.want != "") || result.WinPattern != tt.want {
+				t.Fatalf("won %v with %q, want %q", result.Won, result.WinPattern, tt.want)
+			}
+			if tt.want == "" {
+				if g.Status != "active" {
+					t.Errorf("status = %s, want active", g.Status)
+				}
+				return
+			}
+			if g.Status != "finished" || g.Winner != winner.User || g.WinPattern != tt.want {
+				t.Errorf("game %s, winner %v, pattern %q", g.Status, g.Winner, g.WinPattern)
+			}
+			if win := g.Events[len(g.Events)-1]; win.Type != EventWin || win.Cause != result.Event {
+				t.Errorf("last event = %+v, want a win caused by the claim", win)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package game
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	EventClaim           = "claim"
+	EventGuess           = "guess"
+	EventItemUsed        = "item_used"
+	EventItemEarned      = "item_earned"
+	EventCootiesTransfer = "cooties_transfer"
+	EventTileLost        = "tile_lost"
+	EventCootiesDecay    = "cooties_decay" // the holder lost a tile
+	EventCootiesMoved    = "cooties_moved" // Cooties moved on by themselves
+	EventWin             = "win"
+	EventGameFinished    = "game_finished"
+	EventDisputeOpened   = "dispute_opened"
+	EventDisputeResolved = "dispute_resolved"
+)
+
+// Event is one entry in a game's history. Side effects point back at the
+// event that caused them so they can be undone together.
+type Event struct {
+	Seq      int                `bson:"seq" json:"seq"`
+	Type     string             `bson:"type" json:"type"`
+	Actor    primitive.ObjectID `bson:"actor,omitempty" json:"actor,omitempty"`
+	Target   primitive.ObjectID `bson:"target,omitempty" json:"target,omitempty"`
+	Tiles    []int              `bson:"tiles,omitempty" json:"tiles,omitempty"`
+	Item     string             `bson:"item,omitempty" json:"item,omitempty"`
+	Correct  bool               `bson:"correct,omitempty" json:"correct,omitempty"` // guesses only
+	Pattern  Pattern            `bson:"pattern,omitempty" json:"pattern,omitempty"`
+	Cause    int                `bson:"cause,omitempty" json:"cause,omitempty"`     // seq of the causing event
+	Dispute  int                `bson:"dispute,omitempty" json:"dispute,omitempty"` // dispute events only
+	Reverted bool               `bson:"reverted,omitempty" json:"reverted,omitempty"`
+	Forced   bool               `bson:"forced,omitempty" json:"forced,omitempty"` // game ended by an administrator
+	Since    int                `bson:"since,omitempty" json:"-"`                 // transfers only: day the actor caught Cooties
+	Time     time.Time          `bson:"time" json:"time"`
+}
+
+// record appends an event and returns its sequence number. Sequence
+// numbers start at 1 so a zero Cause means "no cause".
+func (e *Engine) record(g *Game, ev Event) int {
+	ev.Seq = len(g.Events) + 1
+	ev.Time = e.now()
+	g.Events = append(g.Events, ev)
+	return ev.Seq
+}
+
+// RedactedFor returns the event as the viewer may see it, and false if
+// they may not see it at all. Anything that would point at the Cooties
+// holder is only shown to the players directly involved.
+func (ev Event) RedactedFor(viewer primitive.ObjectID) (Event, bool) {
+	involved := viewer == ev.Actor || viewer == ev.Target
+	switch ev.Type {
+	case EventCootiesTransfer, EventCootiesMoved:
+		return ev, involved
+	case EventCootiesDecay, EventTileLost, EventItemUsed, EventItemEarned:
+		return ev, viewer == ev.Actor
+	case EventGuess:
+		if viewer != ev.Actor {
+			ev.Correct = false
+			ev.Tiles = nil
+		}
+	}
+	ev.Cause = 0
+	return ev, true
+}
+
+func (g *Game) event(seq int) *Event {
+	if seq < 1 || seq > len(g.Events) {
+		return nil
+	}
+	return &g.Events[seq-1]
+}
+
+// revert undoes an event and, first, everything it caused. Effects that
+// have since been overtaken, like Cooties that already moved on, are left
+// alone.
+func (e *Engine) revert(g *Game, seq int) {
+	ev := g.event(seq)
+	if ev == nil || ev.Reverted {
+		return
+	}
+	for i := len(g.Events) - 1; i >= 0; i-- {
+		if g.Events[i].Cause == seq {
+			e.revert(g, g.Events[i].Seq)
+		}
+	}
+	ev.Reverted = true
+
+	actor := g.player(ev.Actor)
+	switch ev.Type {
+	case EventClaim:
+		if actor == nil {
+			return
+		}
+		for _, i := range ev.Tiles {
+			if i >= len(actor.Board) {
+				continue
+			}
+			actor.Board[i].Decayed = false
+			if actor.Board[i].Claimed {
+				actor.Board[i].Claimed = false
+				actor.Board[i].Frozen = false
+				actor.ClaimedCount--
+			}
+		}
+		if ev.Item != "" {
+			actor.Items = append(actor.Items, ev.Item)
+		}
+		if lines := len(completedLines(actor.Board, g.BoardSize)); lines < actor.LinesRewarded {
+			actor.LinesRewarded = lines
+		}
+	case EventCootiesTransfer:
+		target := g.player(ev.Target)
+		if actor != nil && target != nil && target.Cooties {
+			target.Cooties = false
+			actor.Cooties = true
+			g.CootiesSince = ev.Since
+		}
+	case EventItemEarned:
+		if actor == nil {
+			return
+		}
+		for i, item := range actor.Items {
+			if item == ev.Item {
+				actor.Items = append(actor.Items[:i], actor.Items[i+1:]...)
+				break
+			}
+		}
+	case EventWin:
+		g.Status = "active"
+		g.Winner = primitive.NilObjectID
+		g.WinPattern = ""
+	}
+}
@@ -0,0 +1,104 @@
+package game
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRevertClaim(t *testing.T) {
+	te := newTestEngine()
+	g := te.newGame(3, Rules{Mode: ModeBingo})
+	g.Host = g.Players[2]
+	holder, target := &g.PlayerStates[0], &g.PlayerStates[1]
+	fillBoard(g, holder, target.User)
+	holder.Items = []string{ItemDoubleClaim}
+
+	te.nextDay()
+	te.nextDay()
+	result, err := te.Apply(g, holder.User, itemRequest(ItemDoubleClaim, g, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !target.Cooties || g.CootiesSince != 2 {
+		t.Fatalf("Cooties weren't passed on today")
+	}
+
+	d, err := te.OpenDispute(g, target.User, result.Event, "we never met")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := te.ResolveDispute(g, g.Host, d.ID, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if holder.ClaimedCount != 0 || claimedTiles(holder.Board) != 0 {
+		t.Errorf("holder kept %d tiles", holder.ClaimedCount)
+	}
+	if !slices.Equal(holder.Items, []string{ItemDoubleClaim}) {
+		t.Errorf("items = %v, want the double claim back", holder.Items)
+	}
+	if !holder.Cooties || target.Cooties || g.CootiesSince != 0 {
+		t.Errorf("Cooties with the holder %v since day %d, want back with them since day 0", holder.Cooties, g.CootiesSince)
+	}
+	for _, seq := range []int{result.Event, result.Event + 1} {
+		if ev := g.event(seq); !ev.Reverted {
+			t.Errorf("%s not marked reverted", ev.Type)
+		}
+	}
+}
+
+func TestRevertLeavesCootiesThatMovedOn(t *testing.T) {
+	te := newTestEngine()
+	g := te.newGame(3, Rules{Mode: ModeBingo})
+	g.Host = g.Players[2]
+
+	claim, err := te.Apply(g, g.Players[0], claimRequest(g.Players[1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := te.Apply(g, g.Players[1], claimRequest(g.Players[2])); err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := te.OpenDispute(g, g.Players[1], claim.Event, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := te.VoteDispute(g, g.Players[2], d.ID, false); err != nil {
+		t.Fatal(err)
+	}
+	if g.PlayerStates[0].Cooties || !g.PlayerStates[2].Cooties {
+		t.Error("reverting the first claim took Cooties back from a later holder")
+	}
+}
+
+func TestRevertWin(t *testing.T) {
+	te := newTestEngine()
+	g := te.newGame(3, Rules{Mode: ModeBingo, Items: true, StreakDays: 5})
+	g.Host = g.Players[0]
+	winner := &g.PlayerStates[1]
+	fillBoard(g, winner, g.Players[2], 0, 1)
+
+	result, err := te.Apply(g, winner.User, claimRequest(g.Players[2]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Won || len(winner.Items) != 1 {
+		t.Fatalf("won %v with items %v, want a win and an item for the line", result.Won, winner.Items)
+	}
+
+	d, err := te.OpenDispute(g, g.Players[2], result.Event, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := te.ResolveDispute(g, g.Host, d.ID, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if g.Status != "active" || !g.Winner.IsZero() || g.WinPattern != "" {
+		t.Errorf("game %s won by %v with %q, want active again", g.Status, g.Winner, g.WinPattern)
+	}
+	if len(winner.Items) != 0 || winner.LinesRewarded != 0 || winner.Board[2].Claimed {
+		t.Errorf("items %v, lines rewarded %d, want the claim's rewards gone", winner.Items, winner.LinesRewarded)
+	}
+}
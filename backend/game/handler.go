@@ -2,8 +2,11 @@ package game
 
 import (
 	"context"
+	"irl-mafia-game/auth"
+	"irl-mafia-game/friends"
 	"irl-mafia-game/user"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -12,20 +15,45 @@ import (
 
 // Requests
 type CreateGameRequest struct {
-	PlayerIDs []string `json:"playerIds"`
-	BoardSize int      `json:"boardSize"`
+	PlayerIDs  []string  `json:"playerIds"`
+	BoardSize  int       `json:"boardSize"`
+	Patterns   []string  `json:"patterns"`   // line, corners, x, plus, blackout
+	FreeCenter bool      `json:"freeCenter"` // odd boards only
+	Rules      *Rules    `json:"rules"`      // defaults to first-to-bingo
+	Schedule   *Schedule `json:"schedule"`   // defaults to 18:00 UTC
+}
+
+type DisputeRequest struct {
+	Event  int    `json:"event" binding:"required"` // seq of the disputed claim
+	Reason string `json:"reason"`
+}
+
+type DisputeDecisionRequest struct {
+	Uphold bool `json:"uphold"`
+}
+
+type AddBotRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type PlayerInfo struct {
+	ID       string               `json:"id"`
+	Username string               `json:"username"`
+	Bot      bool                 `json:"bot,omitempty"`
+	Profile  user.ProfileResponse `json:"profile"`
 }
 
 type ActionRequest struct {
 	TargetID string `json:"targetId"`
-	Action   string `json:"action"`
+	Action   string `json:"action"` // claim, guess, item
+	Item     string `json:"item"`   // item actions only
 }
 
 // Handlers
 
 // CreateGameHandler godoc
 // @Summary Create a new game
-// @Description Create a new game with a list of player IDs and board size
+// @Description Create a new game with a list of player IDs, board size and winning patterns. Players must be friends of the host.
 // @Tags games
 // @Accept json
 // @Produce json
@@ -33,7 +61,7 @@ type ActionRequest struct {
 // @Success 200 {object} map[string]interface{}
 // @Router /games/create [post]
 // @Security BearerAuth
-func CreateGameHandler(gameRepo GameRepository, userRepo user.UserRepository) gin.HandlerFunc {
+func CreateGameHandler(gameRepo GameRepository, userRepo user.UserRepository, friendRepo friends.FriendRepository, engine *Engine) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var req CreateGameRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
@@ -41,6 +69,43 @@ func CreateGameHandler(gameRepo GameRepository, userRepo user.UserRepository) gi
 			return
 		}
 
+		if req.BoardSize == 0 {
+			req.BoardSize = 3
+		}
+		if req.BoardSize < 3 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "board size must be at least 3"})
+			return
+		}
+
+		patterns, err := ParsePatterns(req.Patterns, req.BoardSize)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if req.FreeCenter && req.BoardSize%2 == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "free center needs an odd board size"})
+			return
+		}
+
+		rules := DefaultRules()
+		if req.Rules != nil {
+			rules = *req.Rules
+		}
+		if err := rules.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		schedule := DefaultSchedule()
+		if req.Schedule != nil {
+			schedule = *req.Schedule
+		}
+		if err := schedule.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
 		var players []primitive.ObjectID
 		for _, id := range req.PlayerIDs {
 			objID, err := primitive.ObjectIDFromHex(id)
@@ -51,12 +116,38 @@ func CreateGameHandler(gameRepo GameRepository, userRepo user.UserRepository) gi
 			players = append(players, objID)
 		}
 
+		var host primitive.ObjectID
+		if userID, exists := c.Get("user_id"); exists {
+			host, _ = primitive.ObjectIDFromHex(userID.(string))
+		}
+
+		for _, playerID := range players {
+			if playerID == host {
+				continue
+			}
+			ok, err := friendRepo.AreFriends(c.Request.Context(), host, playerID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			if !ok {
+				c.JSON(http.StatusForbidden, gin.H{"error": "you can only invite friends"})
+				return
+			}
+		}
+
 		game := Game{
-			Players:   players,
-			BoardSize: req.BoardSize,
-			Status:    "active",
-			CreatedAt: time.Now(),
+			Host:       host,
+			Players:    players,
+			BoardSize:  req.BoardSize,
+			Status:     "active",
+			CreatedAt:  time.Now(),
+			Patterns:   patterns,
+			FreeCenter: req.FreeCenter,
+			Rules:      rules,
+			Schedule:   schedule,
 		}
+		engine.Deal(&game)
 
 		insertedID, err := gameRepo.Create(context.Background(), game)
 		if err != nil {
@@ -80,7 +171,7 @@ func CreateGameHandler(gameRepo GameRepository, userRepo user.UserRepository) gi
 
 // JoinGameHandler godoc
 // @Summary Join an existing game
-// @Description Add a player to an existing game by game ID
+// @Description Add a player to an existing game by game ID. Only friends of the host can join.
 // @Tags games
 // @Accept json
 // @Produce json
@@ -88,103 +179,162 @@ func CreateGameHandler(gameRepo GameRepository, userRepo user.UserRepository) gi
 // @Success 200 {object} map[string]string
 // @Router /games/{id}/join [post]
 // @Security BearerAuth
-func JoinGameHandler(gameRepo GameRepository, userRepo user.UserRepository) gin.HandlerFunc {
+func JoinGameHandler(gameRepo GameRepository, userRepo user.UserRepository, friendRepo friends.FriendRepository, engine *Engine) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		userID, exists := c.Get("user_id")
-		if !exists {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "user ID not found in context"})
+		userObjID, game, ok := LoadGame(c, gameRepo)
+		if !ok {
 			return
 		}
 
-		gameID := c.Param("id")
+		if !friendOfHost(c, friendRepo, game, userObjID) {
+			return
+		}
 
-		gameObjID, err := primitive.ObjectIDFromHex(gameID)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid game ID"})
+		join(c, gameRepo, userRepo, engine, game, userObjID)
+	}
+}
+
+// JoinByInviteHandler godoc
+// @Summary Join a game by invite
+// @Description Join the game an invite code belongs to. Guests can join with the code alone; full accounts must be friends with the host, as for joining by game ID.
+// @Tags games
+// @Produce json
+// @Param code path string true "Invite code"
+// @Success 200 {object} map[string]string
+// @Router /invites/{code}/join [post]
+// @Security BearerAuth
+func JoinByInviteHandler(gameRepo GameRepository, userRepo user.UserRepository, friendRepo friends.FriendRepository, engine *Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userObjID, ok := auth.CurrentUser(c)
+		if !ok {
 			return
 		}
 
-		userObjID, err := primitive.ObjectIDFromHex(userID.(string))
+		game, err := gameRepo.FindByInvite(c.Request.Context(), c.Param("code"))
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+			c.JSON(http.StatusNotFound, gin.H{"error": "invite not found"})
 			return
 		}
 
-		if err := gameRepo.AddPlayer(context.Background(), gameObjID, userObjID); err != nil {
+		// The code stands in for friendship only for guests, who have no
+		// friends to be
+		u, err := userRepo.FindUserWithID(c.Request.Context(), userObjID)
+		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
-
-		err = userRepo.AddGameToUser(context.Background(), userObjID, gameObjID)
-		if err != nil {
-			println("Failed to add game to user:", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update user"})
+		if !u.Guest && !friendOfHost(c, friendRepo, game, userObjID) {
 			return
 		}
 
-		c.JSON(http.StatusOK, gin.H{"status": "joined"})
+		join(c, gameRepo, userRepo, engine, game, userObjID)
 	}
 }
 
+// friendOfHost checks that a user joining a game is the host's friend,
+// or already in it. On failure it writes the error response.
+func friendOfHost(c *gin.Context, friendRepo friends.FriendRepository, game Game, userObjID primitive.ObjectID) bool {
+	if game.player(userObjID) != nil || game.Host.IsZero() || game.Host == userObjID {
+		return true
+	}
+	ok, err := friendRepo.AreFriends(c.Request.Context(), game.Host, userObjID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return false
+	}
+	if !ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only friends of the host can join"})
+		return false
+	}
+	return true
+}
+
+func join(c *gin.Context, gameRepo GameRepository, userRepo user.UserRepository, engine *Engine, game Game, userObjID primitive.ObjectID) {
+	var joinErr error
+	err := Save(c.Request.Context(), gameRepo, &game, func(g *Game) bool {
+		if g.player(userObjID) != nil {
+			return false
+		}
+		joinErr = engine.Join(g, userObjID)
+		return joinErr == nil
+	})
+	if joinErr != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": joinErr.Error()})
+		return
+	}
+	if err != nil {
+		saveFailed(c, err)
+		return
+	}
+
+	if err := userRepo.AddGameToUser(context.Background(), userObjID, game.ID); err != nil {
+		println("Failed to add game to user:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "joined", "gameId": game.ID.Hex()})
+}
+
 // GetGameHandler godoc
 // @Summary Get game details
-// @Description Retrieve game details by game ID
+// @Description Retrieve game details by game ID, as the current user may see them: other players' boards, items and Cooties stay hidden
 // @Tags games
 // @Accept json
 // @Produce json
 // @Param id path string true "Game ID"
-// @Success 200 {object} Game
+// @Success 200 {object} GameView
 // @Router /games/{id} [get]
 // @Security BearerAuth
 func GetGameHandler(repo GameRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		gameID := c.Param("id")
-		gameObjID, err := primitive.ObjectIDFromHex(gameID)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid game ID"})
-			return
-		}
-
-		game, err := repo.GetByID(context.Background(), gameObjID)
-		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "game not found"})
+		userObjID, game, ok := LoadGame(c, repo)
+		if !ok {
 			return
 		}
-
-		c.JSON(http.StatusOK, game)
+		c.JSON(http.StatusOK, game.ViewFor(userObjID))
 	}
 }
 
 // GetAllGamesHandler godoc
 // @Summary Get all games
-// @Description Retrieve a list of all games
+// @Description Retrieve a list of all games, as the current user may see them
 // @Tags games
 // @Accept json
 // @Produce json
-// @Success 200 {array} Game
+// @Success 200 {array} GameView
 // @Router /games [get]
 // @Security BearerAuth
 func GetAllGamesHandler(repo GameRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		userObjID, ok := auth.CurrentUser(c)
+		if !ok {
+			return
+		}
+
 		games, err := repo.GetAllGames(context.Background())
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
-		c.JSON(http.StatusOK, games)
+		views := make([]GameView, len(games))
+		for i := range games {
+			views[i] = games[i].ViewFor(userObjID)
+		}
+		c.JSON(http.StatusOK, views)
 	}
 }
 
 // TODO: duplicate could be refactored
 
 // GetPlayersUsernamesHandler godoc
-// @Summary Get usernames of players in a game
-// @Description Retrieve usernames for all players in a specified game
+// @Summary Get the players in a game
+// @Description Retrieve usernames and profiles for all players in a specified game
 // @Tags games
 // @Accept json
 // @Produce json
 // @Param id path string true "Game ID"
-// @Success 200 {array} string
+// @Success 200 {array} PlayerInfo
 // @Router /games/{id}/players [get]
 // @Security BearerAuth
 func GetPlayersUsernamesHandler(gameRepo GameRepository, userRepo user.UserRepository) gin.HandlerFunc {
@@ -202,18 +352,468 @@ func GetPlayersUsernamesHandler(gameRepo GameRepository, userRepo user.UserRepos
 			return
 		}
 
-		var userNames []string
+		players := []PlayerInfo{}
 
 		for _, userId := range game.Players {
+			if p := game.player(userId); p != nil && (p.Bot || p.Deleted) {
+				players = append(players, PlayerInfo{ID: userId.Hex(), Username: p.PlayerName, Bot: p.Bot})
+				continue
+			}
 			user, err := userRepo.FindUserWithID(c.Request.Context(), userId)
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 				return
 			}
-			userNames = append(userNames, user.Username)
+			players = append(players, PlayerInfo{ID: userId.Hex(), Username: user.Username, Profile: user.Profile.Response()})
 		}
-		c.JSON(http.StatusOK, userNames)
+		c.JSON(http.StatusOK, players)
+	}
+}
+
+// ActionHandler godoc
+// @Summary Take the daily action
+// @Description Perform the current user's action for today in a game
+// @Tags games
+// @Accept json
+// @Produce json
+// @Param id path string true "Game ID"
+// @Param action body ActionRequest true "Action info"
+// @Success 200 {object} ActionResult
+// @Router /games/{id}/actions [post]
+// @Security BearerAuth
+func ActionHandler(repo GameRepository, engine *Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userObjID, game, ok := LoadGame(c, repo)
+		if !ok {
+			return
+		}
+
+		var req ActionRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		var result ActionResult
+		var applyErr error
+		err := Save(c.Request.Context(), repo, &game, func(g *Game) bool {
+			result, applyErr = engine.Apply(g, userObjID, req)
+			return applyErr == nil
+		})
+		if applyErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": applyErr.Error()})
+			return
+		}
+		if err != nil {
+			saveFailed(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// GetStandingsHandler godoc
+// @Summary Get game standings
+// @Description Rank players by score, finishing points games whose last day has passed
+// @Tags games
+// @Accept json
+// @Produce json
+// @Param id path string true "Game ID"
+// @Success 200 {array} Standing
+// @Router /games/{id}/standings [get]
+// @Security BearerAuth
+func GetStandingsHandler(repo GameRepository, engine *Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		gameObjID, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid game ID"})
+			return
+		}
+
+		game, err := repo.GetByID(context.Background(), gameObjID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "game not found"})
+			return
+		}
+
+		if err := Save(c.Request.Context(), repo, &game, engine.Advance); err != nil {
+			saveFailed(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, game.Standings())
+	}
+}
+
+// AddBotHandler godoc
+// @Summary Add a bot player
+// @Description Let the host seat a bot that takes its daily action automatically
+// @Tags games
+// @Accept json
+// @Produce json
+// @Param id path string true "Game ID"
+// @Param bot body AddBotRequest true "Bot info"
+// @Success 200 {object} map[string]string
+// @Router /games/{id}/bots [post]
+// @Security BearerAuth
+func AddBotHandler(repo GameRepository, engine *Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req AddBotRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		userObjID, game, ok := LoadGame(c, repo)
+		if !ok {
+			return
+		}
+		if userObjID != game.Host {
+			c.JSON(http.StatusForbidden, gin.H{"error": ErrNotHost.Error()})
+			return
+		}
+
+		var botID primitive.ObjectID
+		var botErr error
+		err := Save(c.Request.Context(), repo, &game, func(g *Game) bool {
+			botID, botErr = engine.AddBot(g, req.Name)
+			return botErr == nil
+		})
+		if botErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": botErr.Error()})
+			return
+		}
+		if err != nil {
+			saveFailed(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"botId": botID.Hex()})
+	}
+}
+
+// OpenDisputeHandler godoc
+// @Summary Dispute a claim
+// @Description Challenge a claim made against the current user, freezing its tiles until resolved
+// @Tags games
+// @Accept json
+// @Produce json
+// @Param id path string true "Game ID"
+// @Param dispute body DisputeRequest true "Dispute info"
+// @Success 200 {object} Dispute
+// @Router /games/{id}/disputes [post]
+// @Security BearerAuth
+func OpenDisputeHandler(repo GameRepository, engine *Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req DisputeRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		userObjID, game, ok := LoadGame(c, repo)
+		if !ok {
+			return
+		}
+
+		var dispute Dispute
+		var disputeErr error
+		err := Save(c.Request.Context(), repo, &game, func(g *Game) bool {
+			dispute, disputeErr = engine.OpenDispute(g, userObjID, req.Event, req.Reason)
+			return disputeErr == nil
+		})
+		if disputeErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": disputeErr.Error()})
+			return
+		}
+		if err != nil {
+			saveFailed(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, dispute)
+	}
+}
+
+// GetDisputesHandler godoc
+// @Summary List disputes
+// @Description Retrieve all disputes raised in a game
+// @Tags games
+// @Accept json
+// @Produce json
+// @Param id path string true "Game ID"
+// @Success 200 {array} Dispute
+// @Router /games/{id}/disputes [get]
+// @Security BearerAuth
+func GetDisputesHandler(repo GameRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_, game, ok := LoadGame(c, repo)
+		if !ok {
+			return
+		}
+		c.JSON(http.StatusOK, game.Disputes)
+	}
+}
+
+// VoteDisputeHandler godoc
+// @Summary Vote on a dispute
+// @Description Vote to uphold or revert a disputed claim; a majority of uninvolved players decides
+// @Tags games
+// @Accept json
+// @Produce json
+// @Param id path string true "Game ID"
+// @Param disputeId path int true "Dispute ID"
+// @Param vote body DisputeDecisionRequest true "Vote"
+// @Success 200 {object} Dispute
+// @Router /games/{id}/disputes/{disputeId}/votes [post]
+// @Security BearerAuth
+func VoteDisputeHandler(repo GameRepository, engine *Engine) gin.HandlerFunc {
+	return disputeDecisionHandler(repo, engine.VoteDispute)
+}
+
+// ResolveDisputeHandler godoc
+// @Summary Resolve a dispute
+// @Description Let the host uphold or revert a disputed claim, unless they are the claimant or the disputer
+// @Tags games
+// @Accept json
+// @Produce json
+// @Param id path string true "Game ID"
+// @Param disputeId path int true "Dispute ID"
+// @Param decision body DisputeDecisionRequest true "Decision"
+// @Success 200 {object} Dispute
+// @Router /games/{id}/disputes/{disputeId}/resolve [post]
+// @Security BearerAuth
+func ResolveDisputeHandler(repo GameRepository, engine *Engine) gin.HandlerFunc {
+	return disputeDecisionHandler(repo, engine.ResolveDispute)
+}
+
+func disputeDecisionHandler(repo GameRepository, decide func(*Game, primitive.ObjectID, int, bool) (Dispute, error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		disputeID, err := strconv.Atoi(c.Param("disputeId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid dispute ID"})
+			return
+		}
+
+		var req DisputeDecisionRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		userObjID, game, ok := LoadGame(c, repo)
+		if !ok {
+			return
+		}
+
+		var dispute Dispute
+		var decideErr error
+		err = Save(c.Request.Context(), repo, &game, func(g *Game) bool {
+			dispute, decideErr = decide(g, userObjID, disputeID, req.Uphold)
+			return decideErr == nil
+		})
+		if decideErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": decideErr.Error()})
+			return
+		}
+		if err != nil {
+			saveFailed(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, dispute)
+	}
+}
+
+// GetReportHandler godoc
+// @Summary Get a claim abuse report
+// @Description Let the host see suspicious claim patterns involving a game's players
+// @Tags games
+// @Accept json
+// @Produce json
+// @Param id path string true "Game ID"
+// @Success 200 {array} Flag
+// @Router /games/{id}/report [get]
+// @Security BearerAuth
+func GetReportHandler(repo GameRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userObjID, game, ok := LoadGame(c, repo)
+		if !ok {
+			return
+		}
+		if userObjID != game.Host {
+			c.JSON(http.StatusForbidden, gin.H{"error": ErrNotHost.Error()})
+			return
+		}
+
+		flags, err := Report(c.Request.Context(), repo, game)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, flags)
+	}
+}
+
+// UpdateScheduleHandler godoc
+// @Summary Change a game's schedule
+// @Description Let the host set the local time daily reminders and digests go out
+// @Tags games
+// @Accept json
+// @Produce json
+// @Param id path string true "Game ID"
+// @Param schedule body Schedule true "Schedule"
+// @Success 200 {object} Schedule
+// @Router /games/{id}/schedule [put]
+// @Security BearerAuth
+func UpdateScheduleHandler(repo GameRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var schedule Schedule
+		if err := c.ShouldBindJSON(&schedule); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err := schedule.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		userObjID, game, ok := LoadGame(c, repo)
+		if !ok {
+			return
+		}
+		if userObjID != game.Host {
+			c.JSON(http.StatusForbidden, gin.H{"error": ErrNotHost.Error()})
+			return
+		}
+
+		err := Save(c.Request.Context(), repo, &game, func(g *Game) bool {
+			g.Schedule = schedule
+			return true
+		})
+		if err != nil {
+			saveFailed(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, schedule)
+	}
+}
+
+// CreateInviteHandler godoc
+// @Summary Get an invite code
+// @Description Return the game's invite code, creating one if needed. Friends of the host can join with the code, and so can people without an account, as guests. Host only.
+// @Tags games
+// @Produce json
+// @Param id path string true "Game ID"
+// @Success 200 {object} map[string]string
+// @Router /games/{id}/invite [post]
+// @Security BearerAuth
+func CreateInviteHandler(repo GameRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userObjID, game, ok := LoadGame(c, repo)
+		if !ok {
+			return
+		}
+		if userObjID != game.Host {
+			c.JSON(http.StatusForbidden, gin.H{"error": ErrNotHost.Error()})
+			return
+		}
+
+		if game.Invite == "" {
+			code, err := NewInviteCode()
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate code"})
+				return
+			}
+			err = Save(c.Request.Context(), repo, &game, func(g *Game) bool {
+				if g.Invite != "" {
+					return false
+				}
+				g.Invite = code
+				return true
+			})
+			if err != nil {
+				saveFailed(c, err)
+				return
+			}
+		}
+
+		c.JSON(http.StatusOK, gin.H{"code": game.Invite})
+	}
+}
+
+// RevokeInviteHandler godoc
+// @Summary Revoke the invite code
+// @Description Stop the current invite code from working. Host only.
+// @Tags games
+// @Produce json
+// @Param id path string true "Game ID"
+// @Success 200 {object} map[string]string
+// @Router /games/{id}/invite [delete]
+// @Security BearerAuth
+func RevokeInviteHandler(repo GameRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userObjID, game, ok := LoadGame(c, repo)
+		if !ok {
+			return
+		}
+		if userObjID != game.Host {
+			c.JSON(http.StatusForbidden, gin.H{"error": ErrNotHost.Error()})
+			return
+		}
+
+		err := Save(c.Request.Context(), repo, &game, func(g *Game) bool {
+			g.Invite = ""
+			return true
+		})
+		if err != nil {
+			saveFailed(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"status": "revoked"})
+	}
+}
+
+// saveFailed writes the response for a game that couldn't be saved.
+func saveFailed(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	if err == ErrConflict {
+		status = http.StatusConflict
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
+
+// LoadGame reads the current user and the game named in the path. On
+// failure it writes the error response and returns false.
+func LoadGame(c *gin.Context, repo GameRepository) (primitive.ObjectID, Game, bool) {
+	userObjID, ok := auth.CurrentUser(c)
+	if !ok {
+		return primitive.NilObjectID, Game{}, false
+	}
+	game, ok := FindGame(c, repo)
+	if !ok {
+		return primitive.NilObjectID, Game{}, false
+	}
+	return userObjID, game, true
+}
+
+// FindGame reads the game named in the path, whoever asks for it.
+func FindGame(c *gin.Context, repo GameRepository) (Game, bool) {
+	gameObjID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid game ID"})
+		return Game{}, false
+	}
+
+	game, err := repo.GetByID(c.Request.Context(), gameObjID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "game not found"})
+		return Game{}, false
 	}
+	return game, true
 }
 
-// TODO: Implement ActionHandler and other game-related handlers
+// TODO: Implement other game-related handlers
@@ -0,0 +1,122 @@
+package game
+
+import (
+	"context"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Hub fans out saved game events to the streams open in this process.
+// Subscribers get the full event list and skip what they already sent, so
+// a dropped update is caught up by the next one. Listeners instead get
+// only the events that are new in each save.
+type Hub struct {
+	mu        sync.Mutex
+	subs      map[primitive.ObjectID]map[chan Update]struct{}
+	listeners []Listener
+}
+
+// Update is what a stream receives: the game's full event list, or a
+// notice from outside the engine such as a chat message.
+type Update struct {
+	Events []Event
+	Notice *Notice
+}
+
+// Notice is pushed to streams as is. Unlike events it can't be resumed.
+type Notice struct {
+	Type string `json:"type"`
+	Data any    `json:"data"`
+}
+
+// Listener is called with a saved game and the events new in that save.
+type Listener func(g Game, events []Event)
+
+func NewHub() *Hub {
+	return &Hub{subs: make(map[primitive.ObjectID]map[chan Update]struct{})}
+}
+
+func (h *Hub) Listen(l Listener) {
+	h.mu.Lock()
+	h.listeners = append(h.listeners, l)
+	h.mu.Unlock()
+}
+
+// Subscribe returns a channel of updates for a game and a function to
+// stop listening.
+func (h *Hub) Subscribe(gameID primitive.ObjectID) (<-chan Update, func()) {
+	ch := make(chan Update, 16)
+
+	h.mu.Lock()
+	if h.subs[gameID] == nil {
+		h.subs[gameID] = make(map[chan Update]struct{})
+	}
+	h.subs[gameID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	return ch, func() {
+		h.mu.Lock()
+		delete(h.subs[gameID], ch)
+		if len(h.subs[gameID]) == 0 {
+			delete(h.subs, gameID)
+		}
+		h.mu.Unlock()
+	}
+}
+
+// Publish sends a game's events to its streams and the new ones, those
+// after seq since, to listeners.
+func (h *Hub) Publish(g Game, since int) {
+	h.mu.Lock()
+	for ch := range h.subs[g.ID] {
+		select {
+		case ch <- Update{Events: g.Events}:
+		default: // slow subscriber, it catches up on the next publish
+		}
+	}
+	listeners := h.listeners
+	h.mu.Unlock()
+
+	if since >= len(g.Events) {
+		return
+	}
+	for _, l := range listeners {
+		l(g, g.Events[since:])
+	}
+}
+
+// Notify pushes a notice to a game's streams. Slow subscribers miss it.
+func (h *Hub) Notify(gameID primitive.ObjectID, n Notice) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs[gameID] {
+		select {
+		case ch <- Update{Notice: &n}:
+		default:
+		}
+	}
+}
+
+// publishingRepository publishes a game's events to a Hub whenever the
+// game is saved.
+type publishingRepository struct {
+	GameRepository
+	hub *Hub
+}
+
+func NewPublishingRepository(repo GameRepository, hub *Hub) GameRepository {
+	return &publishingRepository{GameRepository: repo, hub: hub}
+}
+
+func (r *publishingRepository) Update(ctx context.Context, g Game) error {
+	since := len(g.Events)
+	if old, err := r.GameRepository.GetByID(ctx, g.ID); err == nil {
+		since = len(old.Events)
+	}
+	if err := r.GameRepository.Update(ctx, g); err != nil {
+		return err
+	}
+	r.hub.Publish(g, since)
+	return nil
+}
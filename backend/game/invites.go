@@ -0,0 +1,31 @@
+package game
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Join adds a player to a game that hasn't started. Boards are re-dealt
+// so existing players get tiles for the newcomer.
+func (e *Engine) Join(g *Game, userID primitive.ObjectID) error {
+	if g.player(userID) != nil {
+		return nil
+	}
+	if g.Started() {
+		return ErrGameStarted
+	}
+	g.Players = append(g.Players, userID)
+	e.Deal(g)
+	return nil
+}
+
+// NewInviteCode returns a code for an invite link.
+func NewInviteCode() (string, error) {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
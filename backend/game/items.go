@@ -0,0 +1,92 @@
+package game
+
+const (
+	ItemMask        = "mask"         // immune to Cooties transfer for the day
+	ItemThermometer = "thermometer"  // learn whether one player has Cooties
+	ItemDoubleClaim = "double_claim" // claim two of a player's tiles at once
+	ItemShield      = "shield"       // no tile loss from a wrong guess today
+)
+
+var allItems = []string{ItemMask, ItemThermometer, ItemDoubleClaim, ItemShield}
+
+// free reports whether the action leaves the daily action unspent. Masks
+// and Shields only protect the rest of the day, so they can be raised
+// before claiming or guessing.
+func (req ActionRequest) free() bool {
+	return req.Action == ActionItem && (req.Item == ItemMask || req.Item == ItemShield)
+}
+
+// useItem spends one item from the actor's inventory.
+func (e *Engine) useItem(g *Game, actor *Player, req ActionRequest) (ActionResult, error) {
+	slot := -1
+	for i, item := range actor.Items {
+		if item == req.Item {
+			slot = i
+			break
+		}
+	}
+	if slot < 0 {
+		for _, item := range allItems {
+			if item == req.Item {
+				return ActionResult{}, ErrNoSuchItem
+			}
+		}
+		return ActionResult{}, ErrUnknownItem
+	}
+
+	var result ActionResult
+	switch req.Item {
+	case ItemMask, ItemShield:
+		if req.Item == ItemMask {
+			actor.MaskedOn = e.now()
+		} else {
+			actor.ShieldedOn = e.now()
+		}
+	case ItemThermometer, ItemDoubleClaim:
+		target, err := g.target(actor.User, req.TargetID)
+		if err != nil {
+			return ActionResult{}, err
+		}
+		if req.Item == ItemThermometer {
+			hasCooties := target.Cooties
+			result.HasCooties = &hasCooties
+		} else if result, err = e.claim(g, actor, target, 2, req.Item); err != nil {
+			return ActionResult{}, err
+		}
+	}
+
+	actor.Items = append(actor.Items[:slot], actor.Items[slot+1:]...)
+	if result.Event == 0 {
+		result.Event = e.record(g, Event{Type: EventItemUsed, Actor: actor.User, Item: req.Item})
+	}
+	result.Action = ActionItem
+	result.Item = req.Item
+	return result, nil
+}
+
+// awardItems hands out a random item for every newly completed line and
+// for every streak of StreakDays days in a row.
+func (e *Engine) awardItems(g *Game, actor *Player, cause int) []string {
+	rules := g.rules()
+	if !rules.Items {
+		return nil
+	}
+
+	count := 0
+	if lines := len(completedLines(actor.Board, g.BoardSize)); lines > actor.LinesRewarded {
+		count += lines - actor.LinesRewarded
+		actor.LinesRewarded = lines
+	}
+	if actor.Streak > 0 && actor.Streak%rules.StreakDays == 0 {
+		count++
+	}
+
+	var earned []string
+	for i := 0; i < count; i++ {
+		item := allItems[e.rng.Intn(len(allItems))]
+		actor.Items = append(actor.Items, item)
+		earned = append(earned, item)
+		e.record(g, Event{Type: EventItemEarned, Actor: actor.User, Item: item, Cause: cause})
+	}
+	return earned
+}
@@ -0,0 +1,167 @@
+package game
+
+import "testing"
+
+func itemRequest(item string, g *Game, target int) ActionRequest {
+	return ActionRequest{Action: ActionItem, Item: item, TargetID: g.Players[target].Hex()}
+}
+
+func TestUseItem(t *testing.T) {
+	tests := []struct {
+		name     string
+		item     string
+		target   int
+		wantErr  error
+		wantFree bool // leaves the daily action unspent
+		check    func(t *testing.T, g *Game, result ActionResult)
+	}{
+		{name: "mask", item: ItemMask, target: 2, wantFree: true, check: func(t *testing.T, g *Game, result ActionResult) {
+			if g.PlayerStates[1].MaskedOn.IsZero() {
+				t.Error("not masked")
+			}
+		}},
+		{name: "shield", item: ItemShield, target: 2, wantFree: true, check: func(t *testing.T, g *Game, result ActionResult) {
+			if g.PlayerStates[1].ShieldedOn.IsZero() {
+				t.Error("not shielded")
+			}
+		}},
+		{name: "thermometer on the holder", item: ItemThermometer, target: 0, check: func(t *testing.T, g *Game, result ActionResult) {
+			if result.HasCooties == nil || !*result.HasCooties {
+				t.Errorf("has Cooties = %v, want true", result.HasCooties)
+			}
+		}},
+		{name: "thermometer on someone else", item: ItemThermometer, target: 2, check: func(t *testing.T, g *Game, result ActionResult) {
+			if result.HasCooties == nil || *result.HasCooties {
+				t.Errorf("has Cooties = %v, want false", result.HasCooties)
+			}
+		}},
+		{name: "double claim", item: ItemDoubleClaim, target: 2, check: func(t *testing.T, g *Game, result ActionResult) {
+			claim := g.event(result.Event)
+			if len(result.Gained) != 2 || claim.Type != EventClaim || claim.Item != ItemDoubleClaim {
+				t.Errorf("gained %v with %+v, want two tiles in one claim", result.Gained, claim)
+			}
+		}},
+		{name: "double claim on yourself", item: ItemDoubleClaim, target: 1, wantErr: ErrInvalidTarget},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			te := newTestEngine()
+			g := te.newGame(3, Rules{Mode: ModeBingo})
+			user := &g.PlayerStates[1]
+			fillBoard(g, user, g.Players[2])
+			user.Items = []string{tt.item, ItemMask}
+
+			result, err := te.Apply(g, user.User, itemRequest(tt.item, g, tt.target))
+			if err != tt.wantErr {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if len(user.Items) != 2 {
+					t.Errorf("items = %v, want nothing spent", user.Items)
+				}
+				return
+			}
+			if len(user.Items) != 1 || user.Items[0] != ItemMask {
+				t.Errorf("items = %v, want %s spent", user.Items, tt.item)
+			}
+			if result.Action != ActionItem || result.Item != tt.item {
+				t.Errorf("result = %+v", result)
+			}
+			if user.LastAction.IsZero() == !tt.wantFree {
+				t.Errorf("last action = %v, want free %v", user.LastAction, tt.wantFree)
+			}
+			tt.check(t, g, result)
+		})
+	}
+}
+
+func TestUseItemNotOwned(t *testing.T) {
+	te := newTestEngine()
+	g := te.newGame(3, Rules{Mode: ModeBingo})
+	g.PlayerStates[1].Items = []string{ItemMask}
+	for item, want := range map[string]error{ItemShield: ErrNoSuchItem, "cape": ErrUnknownItem} {
+		if _, err := te.Apply(g, g.Players[1], itemRequest(item, g, 2)); err != want {
+			t.Errorf("%s: err = %v, want %v", item, err, want)
+		}
+	}
+}
+
+func TestMaskBlocksCooties(t *testing.T) {
+	te := newTestEngine()
+	g := te.newGame(3, Rules{Mode: ModeBingo})
+	holder, masked := g.Players[0], &g.PlayerStates[1]
+	masked.Items = []string{ItemMask}
+
+	if _, err := te.Apply(g, masked.User, itemRequest(ItemMask, g, 0)); err != nil {
+		t.Fatal(err)
+	}
+	// Masking is free, so the day's claim still works
+	if _, err := te.Apply(g, masked.User, claimRequest(g.Players[2])); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := te.Apply(g, holder, claimRequest(masked.User)); err != nil {
+		t.Fatal(err)
+	}
+	if masked.Cooties || !g.PlayerStates[0].Cooties {
+		t.Error("Cooties got past the mask")
+	}
+
+	// The mask is gone the next day
+	te.nextDay()
+	if _, err := te.Apply(g, holder, claimRequest(masked.User)); err != nil {
+		t.Fatal(err)
+	}
+	if !masked.Cooties {
+		t.Error("the mask still works a day later")
+	}
+}
+
+func TestShieldBlocksTileLoss(t *testing.T) {
+	te := newTestEngine()
+	g := te.newGame(3, Rules{Mode: ModeBingo})
+	guesser := &g.PlayerStates[1]
+	fillBoard(g, guesser, g.Players[2], 0, 1)
+	guesser.Items = []string{ItemShield}
+
+	if _, err := te.Apply(g, guesser.User, itemRequest(ItemShield, g, 2)); err != nil {
+		t.Fatal(err)
+	}
+	result, err := te.Apply(g, guesser.User, ActionRequest{Action: ActionGuess, TargetID: g.Players[2].Hex()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Correct || len(result.Lost) != 0 || guesser.ClaimedCount != 2 {
+		t.Errorf("result = %+v with %d tiles, want a wrong guess that costs nothing", result, guesser.ClaimedCount)
+	}
+}
+
+func TestAwardItems(t *testing.T) {
+	te := newTestEngine()
+	g := te.newGame(3, Rules{Mode: ModePoints, Days: 7, Items: true, StreakDays: 2})
+	player := &g.PlayerStates[1]
+	fillBoard(g, player, g.Players[2], 0, 1)
+
+	// Completing a line earns an item
+	result, err := te.Apply(g, player.User, claimRequest(g.Players[2]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Earned) != 1 || len(player.Items) != 1 || player.LinesRewarded != 1 {
+		t.Fatalf("earned %v, items %v, want one for the line", result.Earned, player.Items)
+	}
+	earned := g.Events[len(g.Events)-1]
+	if earned.Type != EventItemEarned || earned.Item != result.Earned[0] || earned.Cause != result.Event {
+		t.Errorf("last event = %+v, want the item earned by the claim", earned)
+	}
+
+	// So does acting two days in a row, but not another claim on the
+	// same line
+	te.nextDay()
+	result, err = te.Apply(g, player.User, claimRequest(g.Players[2]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if player.Streak != 2 || len(result.Earned) != 1 || len(player.Items) != 2 {
+		t.Errorf("streak %d earned %v, want one item for the streak", player.Streak, result.Earned)
+	}
+}
@@ -7,16 +7,33 @@ import (
 )
 
 type Game struct {
-	ID        primitive.ObjectID   `bson:"_id,omitempty"`
-	Players   []primitive.ObjectID `bson:"players"`
-	BoardSize int                  `bson:"boardSize"`
-	Status    string               `bson:"status"` // active, finished
-	CreatedAt time.Time            `bson:"createdAt"`
+	ID           primitive.ObjectID   `bson:"_id,omitempty"`
+	Host         primitive.ObjectID   `bson:"host,omitempty"`
+	Players      []primitive.ObjectID `bson:"players"`
+	BoardSize    int                  `bson:"boardSize"`
+	Status       string               `bson:"status"` // active, finished
+	CreatedAt    time.Time            `bson:"createdAt"`
+	Patterns     []Pattern            `bson:"patterns"`
+	FreeCenter   bool                 `bson:"freeCenter"`
+	Rules        Rules                `bson:"rules"`
+	Schedule     Schedule             `bson:"schedule"` // when reminders and digests go out
+	PlayerStates []Player             `bson:"playerStates"`
+	Winner       primitive.ObjectID   `bson:"winner,omitempty"`
+	WinPattern   Pattern              `bson:"winPattern,omitempty"`
+	CootiesSince int                  `bson:"cootiesSince"` // day the current holder caught Cooties
+	Ticked       int                  `bson:"ticked"`       // last day whose Cooties effects ran
+	Events       []Event              `bson:"events"`
+	Disputes     []Dispute            `bson:"disputes"`
+	Invite       string               `bson:"invite,omitempty" json:"-"` // code that lets guests and the host's friends join
+	Version      int                  `bson:"version" json:"-"`          // bumped on every save, see Update
 }
 
 type Tile struct {
 	FriendID primitive.ObjectID `bson:"friendId"`
 	Claimed  bool               `bson:"claimed"`
+	Free     bool               `bson:"free,omitempty"`    // free center tile, always claimed
+	Frozen   bool               `bson:"frozen,omitempty"`  // claim under dispute
+	Decayed  bool               `bson:"decayed,omitempty"` // lost to Cooties, still shown as claimed to others
 }
 
 type Board struct {
@@ -24,11 +41,19 @@ type Board struct {
 }
 
 type Player struct {
-	ID           primitive.ObjectID `bson:"_id,omitempty"`
-	PlayerName   string             `bson:"playerName"`
-	User         primitive.ObjectID `bson:"user"`
-	Board        []Tile             `bson:"board"`
-	Cooties      bool               `bson:"cooties"`
-	LastAction   time.Time          `bson:"lastAction"`
-	ClaimedCount int                `bson:"claimedCount"`
+	ID             primitive.ObjectID `bson:"_id,omitempty"`
+	PlayerName     string             `bson:"playerName"`
+	User           primitive.ObjectID `bson:"user"` // generated for bots, which have no user
+	Bot            bool               `bson:"bot,omitempty"`
+	Deleted        bool               `bson:"deleted,omitempty"` // account deleted, PlayerName is a placeholder
+	Board          []Tile             `bson:"board"`
+	Cooties        bool               `bson:"cooties"`
+	LastAction     time.Time          `bson:"lastAction"`
+	ClaimedCount   int                `bson:"claimedCount"`
+	CorrectGuesses int                `bson:"correctGuesses"`
+	Streak         int                `bson:"streak"`        // consecutive days acted
+	LinesRewarded  int                `bson:"linesRewarded"` // lines already turned into items
+	Items          []string           `bson:"items"`
+	MaskedOn       time.Time          `bson:"maskedOn,omitempty"`
+	ShieldedOn     time.Time          `bson:"shieldedOn,omitempty"`
 }
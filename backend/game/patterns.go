@@ -0,0 +1,112 @@
+package game
+
+import "fmt"
+
+// Pattern is a shape of claimed tiles that wins the game.
+type Pattern string
+
+const (
+	PatternLine     Pattern = "line"     // any row, column or diagonal
+	PatternCorners  Pattern = "corners"  // the four corner tiles
+	PatternX        Pattern = "x"        // both diagonals
+	PatternPlus     Pattern = "plus"     // middle row and middle column, odd boards only
+	PatternBlackout Pattern = "blackout" // every tile
+)
+
+var DefaultPatterns = []Pattern{PatternLine}
+
+// ParsePatterns validates pattern names for a board of the given size.
+// An empty list falls back to DefaultPatterns.
+func ParsePatterns(names []string, size int) ([]Pattern, error) {
+	if len(names) == 0 {
+		return DefaultPatterns, nil
+	}
+
+	seen := make(map[Pattern]bool)
+	patterns := make([]Pattern, 0, len(names))
+	for _, name := range names {
+		p := Pattern(name)
+		switch p {
+		case PatternLine, PatternCorners, PatternX, PatternBlackout:
+		case PatternPlus:
+			if size%2 == 0 {
+				return nil, fmt.Errorf("pattern %q needs an odd board size", name)
+			}
+		default:
+			return nil, fmt.Errorf("unknown pattern %q", name)
+		}
+		if !seen[p] {
+			seen[p] = true
+			patterns = append(patterns, p)
+		}
+	}
+	return patterns, nil
+}
+
+// completedPattern returns the first pattern, in the game's order, that is
+// fully claimed on the board.
+func completedPattern(board []Tile, size int, patterns []Pattern) (Pattern, bool) {
+	for _, p := range patterns {
+		for _, cells := range patternCells(p, size) {
+			if allClaimed(board, cells) {
+				return p, true
+			}
+		}
+	}
+	return "", false
+}
+
+// patternCells lists the alternative sets of tile indexes that complete p.
+// Completing any one set is enough.
+func patternCells(p Pattern, size int) [][]int {
+	diag := make([]int, size)
+	anti := make([]int, size)
+	for i := 0; i < size; i++ {
+		diag[i] = i*size + i
+		anti[i] = i*size + (size - 1 - i)
+	}
+
+	switch p {
+	case PatternLine:
+		var lines [][]int
+		for i := 0; i < size; i++ {
+			row := make([]int, size)
+			col := make([]int, size)
+			for j := 0; j < size; j++ {
+				row[j] = i*size + j
+				col[j] = j*size + i
+			}
+			lines = append(lines, row, col)
+		}
+		return append(lines, diag, anti)
+	case PatternCorners:
+		return [][]int{{0, size - 1, size * (size - 1), size*size - 1}}
+	case PatternX:
+		return [][]int{append(diag, anti...)}
+	case PatternPlus:
+		mid := size / 2
+		cells := make([]int, 0, 2*size)
+		for j := 0; j < size; j++ {
+			cells = append(cells, mid*size+j, j*size+mid)
+		}
+		return [][]int{cells}
+	case PatternBlackout:
+		all := make([]int, size*size)
+		for i := range all {
+			all[i] = i
+		}
+		return [][]int{all}
+	}
+	return nil
+}
+
+// allClaimed reports whether every cell is claimed. Tiles frozen by a
+// dispute don't count until it is resolved.
+func allClaimed(board []Tile, cells []int) bool {
+	for _, i := range cells {
+		if i >= len(board) || !board[i].Claimed || board[i].Frozen {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,82 @@
+package game
+
+import (
+	"slices"
+	"testing"
+)
+
+// testBoard is a size x size board with the given tiles claimed.
+func testBoard(size int, claimed ...int) []Tile {
+	board := make([]Tile, size*size)
+	for _, i := range claimed {
+		board[i].Claimed = true
+	}
+	return board
+}
+
+func TestCompletedPattern(t *testing.T) {
+	withFreeCenter := testBoard(3, 3, 5)
+	withFreeCenter[4] = Tile{Claimed: true, Free: true}
+	withFrozen := testBoard(3, 0, 1, 2)
+	withFrozen[1].Frozen = true
+
+	tests := []struct {
+		name     string
+		size     int
+		board    []Tile
+		patterns []Pattern
+		want     Pattern
+	}{
+		{"empty", 3, testBoard(3), []Pattern{PatternLine}, ""},
+		{"row", 3, testBoard(3, 3, 4, 5), []Pattern{PatternLine}, PatternLine},
+		{"column", 3, testBoard(3, 1, 4, 7), []Pattern{PatternLine}, PatternLine},
+		{"diagonal", 3, testBoard(3, 0, 4, 8), []Pattern{PatternLine}, PatternLine},
+		{"anti-diagonal", 3, testBoard(3, 2, 4, 6), []Pattern{PatternLine}, PatternLine},
+		{"broken line", 3, testBoard(3, 0, 1, 5), []Pattern{PatternLine}, ""},
+		{"frozen tile", 3, withFrozen, []Pattern{PatternLine}, ""},
+		{"free center", 3, withFreeCenter, []Pattern{PatternLine}, PatternLine},
+		{"corners", 3, testBoard(3, 0, 2, 6, 8), []Pattern{PatternCorners}, PatternCorners},
+		{"corners need all four", 3, testBoard(3, 0, 2, 6), []Pattern{PatternCorners}, ""},
+		{"corners don't make a line", 3, testBoard(3, 0, 2, 6, 8), []Pattern{PatternLine}, ""},
+		{"corners on 4x4", 4, testBoard(4, 0, 3, 12, 15), []Pattern{PatternCorners}, PatternCorners},
+		{"x", 3, testBoard(3, 0, 2, 4, 6, 8), []Pattern{PatternX}, PatternX},
+		{"x needs both diagonals", 3, testBoard(3, 0, 4, 8), []Pattern{PatternX}, ""},
+		{"plus", 3, testBoard(3, 1, 3, 4, 5, 7), []Pattern{PatternPlus}, PatternPlus},
+		{"plus on 5x5", 5, testBoard(5, 2, 7, 10, 11, 12, 13, 14, 17, 22), []Pattern{PatternPlus}, PatternPlus},
+		{"blackout", 3, testBoard(3, 0, 1, 2, 3, 4, 5, 6, 7, 8), []Pattern{PatternBlackout}, PatternBlackout},
+		{"blackout needs every tile", 3, testBoard(3, 0, 1, 2, 3, 4, 5, 6, 7), []Pattern{PatternBlackout}, ""},
+		{"first in game order", 3, testBoard(3, 0, 1, 2, 6, 8), []Pattern{PatternCorners, PatternLine}, PatternCorners},
+		{"first in game order, reversed", 3, testBoard(3, 0, 1, 2, 6, 8), []Pattern{PatternLine, PatternCorners}, PatternLine},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := completedPattern(tt.board, tt.size, tt.patterns)
+			if got != tt.want || ok != (tt.want != "") {
+				t.Errorf("completedPattern = %q, %v, want %q", got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePatterns(t *testing.T) {
+	tests := []struct {
+		names   []string
+		size    int
+		want    []Pattern
+		wantErr bool
+	}{
+		{nil, 5, DefaultPatterns, false},
+		{[]string{"corners", "x"}, 5, []Pattern{PatternCorners, PatternX}, false},
+		{[]string{"line", "blackout", "line"}, 4, []Pattern{PatternLine, PatternBlackout}, false},
+		{[]string{"plus"}, 5, []Pattern{PatternPlus}, false},
+		{[]string{"plus"}, 4, nil, true},
+		{[]string{"line", "star"}, 5, nil, true},
+		{[]string{"Line"}, 5, nil, true},
+	}
+	for _, tt := range tests {
+		got, err := ParsePatterns(tt.names, tt.size)
+		if (err != nil) != tt.wantErr || !slices.Equal(got, tt.want) {
+			t.Errorf("ParsePatterns(%q, %d) = %q, %v, want %q", tt.names, tt.size, got, err, tt.want)
+		}
+	}
+}
@@ -14,6 +14,38 @@ type GameRepository interface {
 	GetByID(ctx context.Context, id primitive.ObjectID) (Game, error)
 	AddPlayer(ctx context.Context, gameID, playerID primitive.ObjectID) error
 	GetAllGames(ctx context.Context) ([]Game, error)
+	Update(ctx context.Context, g Game) error
+	FindByPlayers(ctx context.Context, playerIDs []primitive.ObjectID) ([]Game, error)
+	CountByStatus(ctx context.Context) (map[string]int64, error)
+	FindByInvite(ctx context.Context, code string) (Game, error)
+}
+
+// ErrConflict is returned by Update when someone else saved the game
+// since it was loaded.
+var ErrConflict = errors.New("the game was changed at the same time, try again")
+
+// saveAttempts is how often Save tries before giving up on a busy game.
+const saveAttempts = 5
+
+// Save applies change to g and saves it. When someone else saved the game
+// in between, g is reloaded and change applied again. change reports
+// whether there is anything to save; it may run several times, so it
+// must only touch g and its own results.
+func Save(ctx context.Context, repo GameRepository, g *Game, change func(g *Game) bool) error {
+	for attempt := 1; ; attempt++ {
+		if !change(g) {
+			return nil
+		}
+		err := repo.Update(ctx, *g)
+		if err != ErrConflict || attempt == saveAttempts {
+			return err
+		}
+		fresh, err := repo.GetByID(ctx, g.ID)
+		if err != nil {
+			return err
+		}
+		*g = fresh
+	}
 }
 
 type mongoRepository struct {
@@ -46,7 +78,7 @@ func (r *mongoRepository) AddPlayer(ctx context.Context, gameID, playerID primit
 	_, err := r.col.UpdateOne(
 		ctx,
 		bson.M{"_id": gameID},
-		bson.M{"$addToSet": bson.M{"players": playerID}},
+		bson.M{"$addToSet": bson.M{"players": playerID}, "$inc": bson.M{"version": 1}},
 	)
 	return err
 }
@@ -62,3 +94,66 @@ func (r *mongoRepository) GetAllGames(ctx context.Context) ([]Game, error) {
 	}
 	return games, nil
 }
+
+// Update saves a game loaded at g.Version, failing with ErrConflict if it
+// has been saved since.
+func (r *mongoRepository) Update(ctx context.Context, g Game) error {
+	loaded := g.Version
+	g.Version++
+	result, err := r.col.ReplaceOne(ctx, bson.M{"_id": g.ID, "version": loaded}, g)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		n, err := r.col.CountDocuments(ctx, bson.M{"_id": g.ID})
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			return ErrConflict
+		}
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// FindByPlayers returns every game that includes any of the given players.
+func (r *mongoRepository) FindByPlayers(ctx context.Context, playerIDs []primitive.ObjectID) ([]Game, error) {
+	var games []Game
+	cursor, err := r.col.Find(ctx, bson.M{"players": bson.M{"$in": playerIDs}})
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(ctx, &games); err != nil {
+		return nil, err
+	}
+	return games, nil
+}
+
+// CountByStatus returns how many games there are in each status.
+func (r *mongoRepository) CountByStatus(ctx context.Context) (map[string]int64, error) {
+	cursor, err := r.col.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{"_id": "$status", "count": bson.M{"$sum": 1}}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		Status string `bson:"_id"`
+		Count  int64  `bson:"count"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
+func (r *mongoRepository) FindByInvite(ctx context.Context, code string) (Game, error) {
+	var g Game
+	err := r.col.FindOne(ctx, bson.M{"invite": code}).Decode(&g)
+	return g, err
+}
@@ -0,0 +1,85 @@
+package game
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// versionedRepo holds one game and checks versions like the mongo
+// repository. conflicts saves fail as if someone else got there first.
+type versionedRepo struct {
+	GameRepository
+	game      Game
+	conflicts int
+}
+
+func (r *versionedRepo) GetByID(ctx context.Context, id primitive.ObjectID) (Game, error) {
+	return r.game, nil
+}
+
+func (r *versionedRepo) Update(ctx context.Context, g Game) error {
+	if r.conflicts > 0 {
+		r.conflicts--
+		r.game.Version++
+		r.game.Schedule.Time = "other"
+	}
+	if g.Version != r.game.Version {
+		return ErrConflict
+	}
+	g.Version++
+	r.game = g
+	return nil
+}
+
+func TestSave(t *testing.T) {
+	tests := []struct {
+		name      string
+		conflicts int
+		wantErr   error
+		wantRuns  int
+	}{
+		{name: "no conflict", wantRuns: 1},
+		{name: "retries from a fresh copy", conflicts: 2, wantRuns: 3},
+		{name: "gives up", conflicts: saveAttempts, wantErr: ErrConflict, wantRuns: saveAttempts},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &versionedRepo{game: Game{ID: primitive.NewObjectID()}, conflicts: tt.conflicts}
+			g := repo.game
+
+			runs := 0
+			err := Save(context.Background(), repo, &g, func(g *Game) bool {
+				runs++
+				g.Players = append(g.Players, primitive.NewObjectID())
+				return true
+			})
+			if err != tt.wantErr {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if runs != tt.wantRuns {
+				t.Errorf("change ran %d times, want %d", runs, tt.wantRuns)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			// The other writer's change survives and ours is applied once
+			if len(repo.game.Players) != 1 {
+				t.Errorf("saved %d players, want 1", len(repo.game.Players))
+			}
+			if tt.conflicts > 0 && repo.game.Schedule.Time != "other" {
+				t.Errorf("the concurrent change was overwritten")
+			}
+		})
+	}
+}
+
+func TestSaveUnchanged(t *testing.T) {
+	repo := &versionedRepo{game: Game{ID: primitive.NewObjectID()}}
+	g := repo.game
+	err := Save(context.Background(), repo, &g, func(g *Game) bool { return false })
+	if err != nil || repo.game.Version != 0 {
+		t.Fatalf("err = %v, version = %d, want nothing saved", err, repo.game.Version)
+	}
+}
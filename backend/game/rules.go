@@ -0,0 +1,215 @@
+package game
+
+import (
+	"errors"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	ModeBingo  = "bingo"  // first completed pattern wins
+	ModePoints = "points" // highest score after Days days wins
+)
+
+// Rules tunes how a game is played and scored.
+type Rules struct {
+	Mode         string `bson:"mode" json:"mode"`
+	Days         int    `bson:"days" json:"days"`
+	TilePoints   int    `bson:"tilePoints" json:"tilePoints"`
+	LinePoints   int    `bson:"linePoints" json:"linePoints"`
+	GuessPoints  int    `bson:"guessPoints" json:"guessPoints"`
+	Items        bool   `bson:"items" json:"items"`               // award power-ups
+	StreakDays   int    `bson:"streakDays" json:"streakDays"`     // days in a row that earn an item
+	Throttle     bool   `bson:"throttle" json:"throttle"`         // refuse repeat mutual claims
+	DecayDays    int    `bson:"decayDays" json:"decayDays"`       // Cooties lose a tile every this many days, 0 never
+	TransferDays int    `bson:"transferDays" json:"transferDays"` // Cooties move on by themselves after this many days, 0 never
+}
+
+// DefaultRules are used for games created without rules. Cooties decay
+// and transfer are still being tuned, so games have to turn them on.
+func DefaultRules() Rules {
+	return Rules{
+		Mode:        ModeBingo,
+		Days:        7,
+		TilePoints:  1,
+		LinePoints:  3,
+		GuessPoints: 2,
+		Items:       true,
+		StreakDays:  3,
+	}
+}
+
+func (r Rules) Validate() error {
+	switch r.Mode {
+	case ModeBingo:
+	case ModePoints:
+		if r.Days < 1 {
+			return errors.New("points games must last at least one day")
+		}
+	default:
+		return errors.New("unknown rules mode")
+	}
+	if r.TilePoints < 0 || r.LinePoints < 0 || r.GuessPoints < 0 {
+		return errors.New("points must not be negative")
+	}
+	if r.DecayDays < 0 || r.TransferDays < 0 {
+		return errors.New("cooties timings must not be negative")
+	}
+	if r.Items && r.StreakDays < 1 {
+		return errors.New("streak days must be at least one")
+	}
+	return nil
+}
+
+type Standing struct {
+	UserID         string `json:"userId"`
+	Score          int    `json:"score"`
+	Lines          int    `json:"lines"`
+	Tiles          int    `json:"tiles"`
+	CorrectGuesses int    `json:"correctGuesses"`
+}
+
+// Standings ranks players by score. Ties are broken by completed lines,
+// then claimed tiles, then correct guesses, then join order. Until the
+// game is over they are worked out from the public boards.
+func (g *Game) Standings() []Standing {
+	rules := g.rules()
+	standings := make([]Standing, len(g.PlayerStates))
+	order := make(map[string]int)
+	for i, p := range g.PlayerStates {
+		board := g.publicBoard(p)
+		lines := len(completedLines(board, g.BoardSize))
+		tiles := claimedTiles(board)
+		standings[i] = Standing{
+			UserID:         p.User.Hex(),
+			Score:          tiles*rules.TilePoints + lines*rules.LinePoints + p.CorrectGuesses*rules.GuessPoints,
+			Lines:          lines,
+			Tiles:          tiles,
+			CorrectGuesses: p.CorrectGuesses,
+		}
+		order[p.User.Hex()] = i
+	}
+
+	sort.SliceStable(standings, func(i, j int) bool {
+		a, b := standings[i], standings[j]
+		switch {
+		case a.Score != b.Score:
+			return a.Score > b.Score
+		case a.Lines != b.Lines:
+			return a.Lines > b.Lines
+		case a.Tiles != b.Tiles:
+			return a.Tiles > b.Tiles
+		case a.CorrectGuesses != b.CorrectGuesses:
+			return a.CorrectGuesses > b.CorrectGuesses
+		}
+		return order[a.UserID] < order[b.UserID]
+	})
+	return standings
+}
+
+// Advance brings the game up to the current time: Cooties decay and move
+// for every day that has started, a points game ends once its last day has
+// passed, and otherwise bots take today's turn. It reports whether the game
+// changed.
+func (e *Engine) Advance(g *Game) bool {
+	rules := g.rules()
+	if g.Status != "active" {
+		return false
+	}
+
+	today := g.Day(e.now())
+	last := today
+	if rules.Mode == ModePoints && last > rules.Days-1 {
+		last = rules.Days - 1
+	}
+	changed := e.tickCooties(g, last)
+
+	if rules.Mode != ModePoints || today < rules.Days {
+		return e.PlayBots(g) || changed
+	}
+
+	e.finish(g, primitive.NilObjectID, false)
+	return true
+}
+
+// ForceFinish ends an active game early on an administrator's say. With
+// no winner given the current leader wins.
+func (e *Engine) ForceFinish(g *Game, winner primitive.ObjectID) error {
+	if g.Status != "active" {
+		return ErrGameNotActive
+	}
+	if !winner.IsZero() && g.player(winner) == nil {
+		return ErrNotInGame
+	}
+	e.finish(g, winner, true)
+	return nil
+}
+
+func (e *Engine) finish(g *Game, winner primitive.ObjectID, forced bool) {
+	g.Status = "finished"
+	g.Winner = winner
+	if standings := g.Standings(); winner.IsZero() && len(standings) > 0 {
+		g.Winner, _ = primitive.ObjectIDFromHex(standings[0].UserID)
+	}
+	e.record(g, Event{Type: EventGameFinished, Actor: g.Winner, Forced: forced})
+}
+
+func (g *Game) rules() Rules {
+	if g.Rules.Mode == "" {
+		return DefaultRules()
+	}
+	return g.Rules
+}
+
+// publicBoard is a player's board as everyone else sees it. While the game
+// is on, tiles lost to Cooties decay still show as claimed, or the drop
+// would give the holder away.
+func (g *Game) publicBoard(p Player) []Tile {
+	if g.Status != "active" {
+		return p.Board
+	}
+	board := make([]Tile, len(p.Board))
+	for i, t := range p.Board {
+		board[i] = t
+		if t.Decayed {
+			board[i].Claimed = true
+		}
+	}
+	return board
+}
+
+// completedLines returns every fully claimed row, column and diagonal.
+func completedLines(board []Tile, size int) [][]int {
+	var lines [][]int
+	for _, cells := range patternCells(PatternLine, size) {
+		if allClaimed(board, cells) {
+			lines = append(lines, cells)
+		}
+	}
+	return lines
+}
+
+// claimedTiles counts claimed tiles, not counting the free center or
+// tiles frozen by a dispute.
+func claimedTiles(board []Tile) int {
+	n := 0
+	for _, t := range board {
+		if t.Claimed && !t.Free && !t.Frozen {
+			n++
+		}
+	}
+	return n
+}
+
+// decayedTiles counts tiles lost to Cooties decay that others still see
+// as claimed.
+func decayedTiles(board []Tile) int {
+	n := 0
+	for _, t := range board {
+		if t.Decayed {
+			n++
+		}
+	}
+	return n
+}
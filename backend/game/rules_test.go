@@ -0,0 +1,100 @@
+package game
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestStandings(t *testing.T) {
+	player := func(name string, guesses int, claimed ...int) Player {
+		return Player{PlayerName: name, User: primitive.NewObjectID(), Board: testBoard(3, claimed...), CorrectGuesses: guesses}
+	}
+	frozen := player("frozen", 0, 0, 1, 2)
+	frozen.Board[4] = Tile{Claimed: true, Free: true}
+	frozen.Board[0].Frozen = true
+
+	g := &Game{
+		BoardSize: 3,
+		Status:    "active",
+		Rules:     Rules{Mode: ModePoints, Days: 7, TilePoints: 1, LinePoints: 3, GuessPoints: 2},
+		PlayerStates: []Player{
+			player("two tiles", 2, 0, 1),
+			player("guesses, joined first", 3),
+			frozen,
+			player("line", 0, 0, 1, 2),
+			player("guesses, joined last", 3),
+			player("six tiles", 0, 0, 1, 3, 5, 7, 8),
+		},
+	}
+
+	want := []struct {
+		name  string
+		score int
+	}{
+		{"line", 6},                  // 3 tiles and a line
+		{"six tiles", 6},             // no line, more tiles
+		{"two tiles", 6},             // fewer tiles, more guesses
+		{"guesses, joined first", 6}, // tied on everything, joined earlier
+		{"guesses, joined last", 6},
+		{"frozen", 2}, // neither the free center nor the frozen tile count
+	}
+	names := make(map[string]string)
+	for _, p := range g.PlayerStates {
+		names[p.User.Hex()] = p.PlayerName
+	}
+	standings := g.Standings()
+	for i, s := range standings {
+		if names[s.UserID] != want[i].name || s.Score != want[i].score {
+			t.Errorf("place %d: %s with %d, want %s with %d", i+1, names[s.UserID], s.Score, want[i].name, want[i].score)
+		}
+	}
+}
+
+func TestPointsGameFinishes(t *testing.T) {
+	te := newTestEngine()
+	g := te.newGame(3, Rules{Mode: ModePoints, Days: 2, TilePoints: 1})
+	leader := g.Players[1]
+	if _, err := te.Apply(g, leader, claimRequest(g.Players[2])); err != nil {
+		t.Fatal(err)
+	}
+
+	te.nextDay()
+	te.Advance(g)
+	if g.Status != "active" {
+		t.Fatalf("status = %s on the last day, want active", g.Status)
+	}
+
+	te.nextDay()
+	if !te.Advance(g) || g.Status != "finished" || g.Winner != leader {
+		t.Fatalf("status = %s, winner %v after the last day, want the leader to win", g.Status, g.Winner)
+	}
+	if ev := g.Events[len(g.Events)-1]; ev.Type != EventGameFinished || ev.Actor != leader || ev.Forced {
+		t.Errorf("last event = %+v, want the game finished", ev)
+	}
+	if _, err := te.Apply(g, g.Players[2], claimRequest(leader)); err != ErrGameNotActive {
+		t.Errorf("err = %v after the game, want %v", err, ErrGameNotActive)
+	}
+}
+
+func TestRulesValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules Rules
+		valid bool
+	}{
+		{"defaults", DefaultRules(), true},
+		{"points", Rules{Mode: ModePoints, Days: 5, TilePoints: 1}, true},
+		{"no mode", Rules{}, false},
+		{"unknown mode", Rules{Mode: "golf"}, false},
+		{"points without days", Rules{Mode: ModePoints}, false},
+		{"negative points", Rules{Mode: ModeBingo, LinePoints: -1}, false},
+		{"negative decay", Rules{Mode: ModeBingo, DecayDays: -1}, false},
+		{"items without streaks", Rules{Mode: ModeBingo, Items: true}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.rules.Validate(); (err == nil) != tt.valid {
+			t.Errorf("%s: err = %v", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package game
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Schedule sets when the daily reminder and digest go out for a game.
+type Schedule struct {
+	Time     string `bson:"time" json:"time"`         // local time of day, HH:MM
+	TimeZone string `bson:"timeZone" json:"timeZone"` // IANA name, e.g. Europe/Berlin
+}
+
+func DefaultSchedule() Schedule {
+	return Schedule{Time: "18:00", TimeZone: "UTC"}
+}
+
+func (s Schedule) Validate() error {
+	if _, err := time.Parse("15:04", s.Time); err != nil {
+		return errors.New("schedule time must be HH:MM")
+	}
+	if _, err := time.LoadLocation(s.TimeZone); err != nil {
+		return errors.New("unknown time zone")
+	}
+	return nil
+}
+
+// Due reports the local date at now, and whether the scheduled time has
+// passed on that date.
+func (s Schedule) Due(now time.Time) (string, bool) {
+	if s.Time == "" {
+		s = DefaultSchedule()
+	}
+	loc, err := time.LoadLocation(s.TimeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+	at, err := time.Parse("15:04", s.Time)
+	if err != nil {
+		return "", false
+	}
+
+	local := now.In(loc)
+	y, m, d := local.Date()
+	due := time.Date(y, m, d, at.Hour(), at.Minute(), 0, 0, loc)
+	return local.Format(time.DateOnly), !local.Before(due)
+}
+
+// ActedToday reports whether the player took their action on the game day
+// containing now.
+func (g *Game) ActedToday(p Player, now time.Time) bool {
+	return g.activeToday(p.LastAction, now)
+}
+
+// PublicEvents returns what everyone may see of a day's events.
+func (g *Game) PublicEvents(day int) []Event {
+	events := []Event{}
+	for _, ev := range g.Events {
+		if g.Day(ev.Time) != day {
+			continue
+		}
+		if public, ok := ev.RedactedFor(primitive.NilObjectID); ok {
+			events = append(events, public)
+		}
+	}
+	return events
+}
@@ -0,0 +1,191 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/net/websocket"
+)
+
+const streamHeartbeat = 30 * time.Second
+
+// StreamEventsHandler godoc
+// @Summary Stream game events
+// @Description Push the events the current user may see as server-sent events. Send Last-Event-ID or lastEventId to resume. Browsers can pass the token as access_token.
+// @Tags games
+// @Produce text/event-stream
+// @Param id path string true "Game ID"
+// @Param lastEventId query int false "Resume after this event"
+// @Success 200 {array} Event
+// @Router /games/{id}/events [get]
+// @Security BearerAuth
+func StreamEventsHandler(repo GameRepository, hub *Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		st, ok := openStream(c, repo, hub)
+		if !ok {
+			return
+		}
+		defer st.close()
+
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+
+		st.run(c.Request.Context(), func(ev *Event, n *Notice) error {
+			var err error
+			switch {
+			case ev != nil:
+				var data []byte
+				if data, err = json.Marshal(ev); err == nil {
+					_, err = fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", ev.Seq, ev.Type, data)
+				}
+			case n != nil:
+				// No id, so notices don't move the client's resume point
+				var data []byte
+				if data, err = json.Marshal(n.Data); err == nil {
+					_, err = fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", n.Type, data)
+				}
+			default:
+				_, err = fmt.Fprint(c.Writer, ": heartbeat\n\n")
+			}
+			c.Writer.Flush()
+			return err
+		})
+	}
+}
+
+// StreamEventsWebSocketHandler godoc
+// @Summary Stream game events over WebSocket
+// @Description Push the events the current user may see as JSON messages. Browsers can pass the token as access_token.
+// @Tags games
+// @Param id path string true "Game ID"
+// @Param lastEventId query int false "Resume after this event"
+// @Success 101
+// @Router /games/{id}/events/ws [get]
+// @Security BearerAuth
+func StreamEventsWebSocketHandler(repo GameRepository, hub *Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		st, ok := openStream(c, repo, hub)
+		if !ok {
+			return
+		}
+		defer st.close()
+
+		websocket.Handler(func(ws *websocket.Conn) {
+			defer ws.Close()
+			ctx, cancel := context.WithCancel(c.Request.Context())
+			defer cancel()
+
+			// Clients don't send anything, reading only notices the close
+			go func() {
+				var ignored string
+				for websocket.Message.Receive(ws, &ignored) == nil {
+				}
+				cancel()
+			}()
+
+			st.run(ctx, func(ev *Event, n *Notice) error {
+				switch {
+				case ev != nil:
+					return websocket.JSON.Send(ws, ev)
+				case n != nil:
+					return websocket.JSON.Send(ws, n)
+				}
+				return websocket.Message.Send(ws, `{"type":"heartbeat"}`)
+			})
+		}).ServeHTTP(c.Writer, c.Request)
+	}
+}
+
+// stream is an open event stream of one game for one viewer.
+type stream struct {
+	viewer  primitive.ObjectID
+	game    Game
+	after   int // seq of the last event the viewer has
+	updates <-chan Update
+	close   func()
+}
+
+// openStream checks the caller plays in the game and reads where to
+// resume from. It subscribes before loading the game, so nothing saved
+// in between is missed; events in both are sent once, by seq.
+func openStream(c *gin.Context, repo GameRepository, hub *Hub) (stream, bool) {
+	st := stream{updates: make(chan Update), close: func() {}}
+	if gameObjID, err := primitive.ObjectIDFromHex(c.Param("id")); err == nil {
+		st.updates, st.close = hub.Subscribe(gameObjID)
+	}
+
+	var ok bool
+	st.viewer, st.game, ok = LoadGame(c, repo)
+	if !ok {
+		st.close()
+		return st, false
+	}
+	if st.game.player(st.viewer) == nil {
+		st.close()
+		c.JSON(http.StatusForbidden, gin.H{"error": ErrNotInGame.Error()})
+		return st, false
+	}
+
+	lastID := c.GetHeader("Last-Event-ID")
+	if q := c.Query("lastEventId"); q != "" {
+		lastID = q
+	}
+	if lastID != "" {
+		n, err := strconv.Atoi(lastID)
+		if err != nil || n < 0 {
+			st.close()
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid last event ID"})
+			return st, false
+		}
+		st.after = n
+	}
+	return st, true
+}
+
+// run sends the backlog after the resume point, then live events and
+// notices, until the context ends or a send fails. Sending neither an
+// event nor a notice asks for a heartbeat.
+func (st *stream) run(ctx context.Context, send func(*Event, *Notice) error) {
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	after := st.after
+	events := st.game.Events
+	for {
+		for _, ev := range events {
+			if ev.Seq <= after {
+				continue
+			}
+			after = ev.Seq
+			if redacted, ok := ev.RedactedFor(st.viewer); ok {
+				if err := send(&redacted, nil); err != nil {
+					return
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			if err := send(nil, nil); err != nil {
+				return
+			}
+		case u := <-st.updates:
+			if u.Notice != nil {
+				if err := send(nil, u.Notice); err != nil {
+					return
+				}
+				continue
+			}
+			events = u.Events
+		}
+	}
+}
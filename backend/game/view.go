@@ -0,0 +1,107 @@
+package game
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GameView is a game as one user may see it: their own board, items and
+// Cooties, everyone's public progress, and the events RedactedFor lets
+// through.
+type GameView struct {
+	ID           string       `json:"id"`
+	Host         string       `json:"host,omitempty"`
+	Players      []string     `json:"players"`
+	BoardSize    int          `json:"boardSize"`
+	Status       string       `json:"status"`
+	CreatedAt    time.Time    `json:"createdAt"`
+	Patterns     []Pattern    `json:"patterns"`
+	FreeCenter   bool         `json:"freeCenter"`
+	Rules        Rules        `json:"rules"`
+	Schedule     Schedule     `json:"schedule"`
+	PlayerStates []PlayerView `json:"playerStates"`
+	Winner       string       `json:"winner,omitempty"`
+	WinPattern   Pattern      `json:"winPattern,omitempty"`
+	Events       []Event      `json:"events"`
+	Disputes     []Dispute    `json:"disputes"`
+}
+
+// PlayerView leaves out everything secret unless it is the viewer's own.
+type PlayerView struct {
+	UserID         string    `json:"userId"`
+	Name           string    `json:"name,omitempty"`
+	Bot            bool      `json:"bot,omitempty"`
+	Deleted        bool      `json:"deleted,omitempty"`
+	ClaimedCount   int       `json:"claimedCount"`
+	CorrectGuesses int       `json:"correctGuesses"`
+	Streak         int       `json:"streak"`
+	Board          []Tile    `json:"board,omitempty"`
+	Cooties        bool      `json:"cooties,omitempty"`
+	Items          []string  `json:"items,omitempty"`
+	LastAction     time.Time `json:"lastAction,omitempty"`
+	MaskedOn       time.Time `json:"maskedOn,omitempty"`
+	ShieldedOn     time.Time `json:"shieldedOn,omitempty"`
+}
+
+// ViewFor returns the game as the viewer may see it.
+func (g *Game) ViewFor(viewer primitive.ObjectID) GameView {
+	view := GameView{
+		ID:           g.ID.Hex(),
+		Players:      make([]string, len(g.Players)),
+		BoardSize:    g.BoardSize,
+		Status:       g.Status,
+		CreatedAt:    g.CreatedAt,
+		Patterns:     g.Patterns,
+		FreeCenter:   g.FreeCenter,
+		Rules:        g.Rules,
+		Schedule:     g.Schedule,
+		PlayerStates: make([]PlayerView, len(g.PlayerStates)),
+		WinPattern:   g.WinPattern,
+		Events:       []Event{},
+		Disputes:     g.Disputes,
+	}
+	if !g.Host.IsZero() {
+		view.Host = g.Host.Hex()
+	}
+	if !g.Winner.IsZero() {
+		view.Winner = g.Winner.Hex()
+	}
+	for i, id := range g.Players {
+		view.Players[i] = id.Hex()
+	}
+
+	for i, p := range g.PlayerStates {
+		pv := PlayerView{
+			UserID:         p.User.Hex(),
+			Name:           p.PlayerName,
+			Bot:            p.Bot,
+			Deleted:        p.Deleted,
+			ClaimedCount:   p.ClaimedCount,
+			CorrectGuesses: p.CorrectGuesses,
+			Streak:         p.Streak,
+		}
+		if p.User != viewer && g.Status == "active" {
+			pv.ClaimedCount += decayedTiles(p.Board)
+		}
+		if p.User == viewer {
+			pv.Board = p.Board
+			pv.Cooties = p.Cooties
+			pv.Items = p.Items
+			pv.LastAction = p.LastAction
+			pv.MaskedOn = p.MaskedOn
+			pv.ShieldedOn = p.ShieldedOn
+		}
+		view.PlayerStates[i] = pv
+	}
+
+	for _, ev := range g.Events {
+		if redacted, ok := ev.RedactedFor(viewer); ok {
+			view.Events = append(view.Events, redacted)
+		}
+	}
+	if view.Disputes == nil {
+		view.Disputes = []Dispute{}
+	}
+	return view
+}
@@ -0,0 +1,58 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers email. Production setups plug in a real provider.
+type Mailer interface {
+	Send(ctx context.Context, m Message) error
+}
+
+// LogMailer writes messages to a writer instead of sending them, for local
+// development.
+type LogMailer struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewLogMailer(w io.Writer) *LogMailer {
+	return &LogMailer{w: w}
+}
+
+// NewFileMailer appends messages to the file at path.
+func NewFileMailer(path string) (*LogMailer, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return NewLogMailer(f), nil
+}
+
+func (l *LogMailer) Send(ctx context.Context, m Message) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err := fmt.Fprintf(l.w, "--- %s\nTo: %s\nSubject: %s\n\n%s\n\n", time.Now().Format(time.RFC3339), m.To, m.Subject, m.Body)
+	return err
+}
+
+// FromEnv picks the mailer configured by MAIL_FILE, falling back to the
+// server log.
+func FromEnv() (Mailer, error) {
+	if path := os.Getenv("MAIL_FILE"); path != "" {
+		return NewFileMailer(path)
+	}
+	return NewLogMailer(log.Writer()), nil
+}
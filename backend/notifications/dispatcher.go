@@ -0,0 +1,150 @@
+package notifications
+
+import (
+	"context"
+	"irl-mafia-game/game"
+	"irl-mafia-game/user"
+	"log"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Dispatcher turns game events into notifications and delivers them to
+// every registered device of each recipient.
+type Dispatcher struct {
+	devices  DeviceRepository
+	users    user.UserRepository
+	provider Provider
+}
+
+func NewDispatcher(devices DeviceRepository, users user.UserRepository, provider Provider) *Dispatcher {
+	return &Dispatcher{devices: devices, users: users, provider: provider}
+}
+
+// GameListener notifies players about new game events in the background.
+func (d *Dispatcher) GameListener() game.Listener {
+	return func(g game.Game, events []game.Event) {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			if err := d.Send(ctx, d.ForEvents(ctx, g, events)); err != nil {
+				log.Println("Failed to send notifications:", err)
+			}
+		}()
+	}
+}
+
+// Send delivers notifications, dropping tokens the provider reports as
+// unregistered.
+func (d *Dispatcher) Send(ctx context.Context, notes []Notification) error {
+	var msgs []Message
+	for _, n := range notes {
+		devices, err := d.devices.FindByUser(ctx, n.UserID)
+		if err != nil {
+			return err
+		}
+		for _, device := range devices {
+			msgs = append(msgs, Message{To: device.Token, Title: n.Title, Body: n.Body, Data: n.Data, Sound: "default"})
+		}
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	invalid, err := d.provider.Send(ctx, msgs)
+	for _, token := range invalid {
+		if rerr := d.devices.RemoveToken(ctx, token); rerr != nil {
+			log.Println("Failed to remove device token:", rerr)
+		}
+	}
+	return err
+}
+
+// ForEvents builds the notifications for new events in a game. Nobody is
+// told about their own actions, and nothing reveals the Cooties holder to
+// players who couldn't already see it on the event stream.
+func (d *Dispatcher) ForEvents(ctx context.Context, g game.Game, events []game.Event) []Notification {
+	var notes []Notification
+	add := func(to primitive.ObjectID, ev game.Event, title, body string) {
+		if to.IsZero() || noAccount(g, to) {
+			return
+		}
+		data := map[string]string{"gameId": g.ID.Hex(), "event": ev.Type}
+		if ev.Dispute != 0 {
+			data["disputeId"] = strconv.Itoa(ev.Dispute)
+		}
+		notes = append(notes, Notification{UserID: to, Title: title, Body: body, Data: data})
+	}
+
+	for _, ev := range events {
+		switch ev.Type {
+		case game.EventClaim:
+			add(ev.Target, ev, "You were claimed", d.name(ctx, g, ev.Actor)+" claimed your tile. Dispute it if you never met.")
+		case game.EventCootiesTransfer:
+			add(ev.Target, ev, "You caught Cooties", "Pass them on by claiming someone.")
+		case game.EventCootiesMoved:
+			add(ev.Actor, ev, "Your Cooties are gone", "They moved on to someone else.")
+			add(ev.Target, ev, "You caught Cooties", "Pass them on by claiming someone.")
+		case game.EventCootiesDecay:
+			add(ev.Actor, ev, "Cooties strike", "You lost a tile to Cooties.")
+		case game.EventWin:
+			for _, p := range g.Players {
+				if p != ev.Actor {
+					add(p, ev, "Bingo!", d.name(ctx, g, ev.Actor)+" got bingo with "+string(ev.Pattern)+".")
+				}
+			}
+		case game.EventGameFinished:
+			body := d.name(ctx, g, ev.Actor) + " finished with the top score."
+			switch {
+			case ev.Actor.IsZero():
+				body = "The game ended with no winner."
+			case ev.Forced:
+				body = "The game was ended early. " + d.name(ctx, g, ev.Actor) + " wins."
+			}
+			for _, p := range g.Players {
+				add(p, ev, "Game over", body)
+			}
+		case game.EventDisputeOpened:
+			add(ev.Target, ev, "Claim disputed", d.name(ctx, g, ev.Actor)+" says you never met.")
+			body := d.name(ctx, g, ev.Actor) + " disputed a claim by " + d.name(ctx, g, ev.Target) + "."
+			if g.Host != ev.Target && g.Host != ev.Actor {
+				add(g.Host, ev, "A claim needs your decision", body)
+				continue
+			}
+			// The host can't decide a dispute they are part of, so the
+			// other players vote on it
+			for _, p := range g.Players {
+				if p != ev.Actor && p != ev.Target {
+					add(p, ev, "A claim needs your vote", body)
+				}
+			}
+		case game.EventDisputeResolved:
+			add(ev.Actor, ev, "Dispute resolved", "Your dispute has been decided.")
+			add(ev.Target, ev, "Dispute resolved", "The dispute over your claim has been decided.")
+		}
+	}
+	return notes
+}
+
+func (d *Dispatcher) name(ctx context.Context, g game.Game, id primitive.ObjectID) string {
+	for _, p := range g.PlayerStates {
+		if p.User == id && (p.Bot || p.Deleted) {
+			return p.PlayerName
+		}
+	}
+	if u, err := d.users.FindUserWithID(ctx, id); err == nil {
+		return u.Username
+	}
+	return "Someone"
+}
+
+func noAccount(g game.Game, id primitive.ObjectID) bool {
+	for _, p := range g.PlayerStates {
+		if p.User == id {
+			return p.Bot || p.Deleted
+		}
+	}
+	return false
+}
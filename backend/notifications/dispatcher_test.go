@@ -0,0 +1,89 @@
+package notifications
+
+import (
+	"context"
+	"irl-mafia-game/game"
+	"irl-mafia-game/user"
+	"slices"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type namedUsers struct {
+	user.UserRepository
+	names map[primitive.ObjectID]string
+}
+
+func (u namedUsers) FindUserWithID(ctx context.Context, id primitive.ObjectID) (user.User, error) {
+	if name, ok := u.names[id]; ok {
+		return user.User{ID: id, Username: name}, nil
+	}
+	return user.User{}, mongo.ErrNoDocuments
+}
+
+func TestForEvents(t *testing.T) {
+	host, alice, bob, bot := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	names := map[primitive.ObjectID]string{host: "host", alice: "alice", bob: "bob", bot: "bot"}
+	g := game.Game{
+		ID:      primitive.NewObjectID(),
+		Host:    host,
+		Players: []primitive.ObjectID{host, alice, bob, bot},
+		PlayerStates: []game.Player{
+			{PlayerName: "host", User: host},
+			{PlayerName: "alice", User: alice},
+			{PlayerName: "bob", User: bob},
+			{PlayerName: "bot", User: bot, Bot: true},
+		},
+	}
+	d := NewDispatcher(nil, namedUsers{names: names}, nil)
+
+	tests := []struct {
+		name  string
+		event game.Event
+		want  []string
+	}{
+		{"claim", game.Event{Type: game.EventClaim, Actor: alice, Target: bob}, []string{"bob"}},
+		{"claim of a bot", game.Event{Type: game.EventClaim, Actor: alice, Target: bot}, nil},
+		{"Cooties passed on", game.Event{Type: game.EventCootiesTransfer, Actor: alice, Target: bob}, []string{"bob"}},
+		{"Cooties moved on", game.Event{Type: game.EventCootiesMoved, Actor: alice, Target: bob}, []string{"alice", "bob"}},
+		{"Cooties decay", game.Event{Type: game.EventCootiesDecay, Actor: alice, Tiles: []int{3}}, []string{"alice"}},
+		{"guess", game.Event{Type: game.EventGuess, Actor: alice, Target: bob, Correct: true}, nil},
+		{"win", game.Event{Type: game.EventWin, Actor: alice, Pattern: game.PatternLine}, []string{"bob", "host"}},
+		{"dispute", game.Event{Type: game.EventDisputeOpened, Actor: bob, Target: alice, Dispute: 1}, []string{"alice", "host"}},
+		{"dispute by the host", game.Event{Type: game.EventDisputeOpened, Actor: host, Target: alice, Dispute: 1}, []string{"alice", "bob"}},
+		{"dispute against the host", game.Event{Type: game.EventDisputeOpened, Actor: bob, Target: host, Dispute: 1}, []string{"alice", "host"}},
+		{"dispute resolved", game.Event{Type: game.EventDisputeResolved, Actor: bob, Target: alice}, []string{"alice", "bob"}},
+		{"finished early with no winner", game.Event{Type: game.EventGameFinished, Forced: true}, []string{"alice", "bob", "host"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notes := d.ForEvents(context.Background(), g, []game.Event{tt.event})
+
+			var got []string
+			for _, n := range notes {
+				got = append(got, names[n.UserID])
+
+				// Nobody hears about what they did themselves
+				if n.UserID == tt.event.Actor && tt.event.Type != game.EventCootiesMoved &&
+					tt.event.Type != game.EventCootiesDecay && tt.event.Type != game.EventDisputeResolved {
+					t.Errorf("%s was told about their own %s", names[n.UserID], tt.event.Type)
+				}
+				// Nor about anything the event stream hides from them,
+				// like who has Cooties
+				if _, ok := tt.event.RedactedFor(n.UserID); !ok {
+					t.Errorf("%s was told about a %s they can't see", names[n.UserID], tt.event.Type)
+				}
+				if n.Data["gameId"] != g.ID.Hex() || n.Data["event"] != tt.event.Type ||
+					(tt.event.Dispute != 0) != (n.Data["disputeId"] == "1") {
+					t.Errorf("data = %v", n.Data)
+				}
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("notified %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package notifications
+
+import (
+	"irl-mafia-game/auth"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type RegisterDeviceRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Platform string `json:"platform"`
+}
+
+// RegisterDeviceHandler godoc
+// @Summary Register a push device
+// @Description Register a push notification token for the current user
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param device body RegisterDeviceRequest true "Device info"
+// @Success 200 {object} map[string]string
+// @Router /users/me/devices [post]
+// @Security BearerAuth
+func RegisterDeviceHandler(repo DeviceRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userObjID, ok := auth.CurrentUser(c)
+		if !ok {
+			return
+		}
+
+		var req RegisterDeviceRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		device := Device{
+			UserID:    userObjID,
+			Token:     req.Token,
+			Platform:  req.Platform,
+			CreatedAt: time.Now(),
+		}
+		if err := repo.AddDevice(c.Request.Context(), device); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"status": "registered"})
+	}
+}
+
+// RemoveDeviceHandler godoc
+// @Summary Remove a push device
+// @Description Stop sending push notifications to a token of the current user
+// @Tags users
+// @Produce json
+// @Param token path string true "Device token"
+// @Success 200 {object} map[string]string
+// @Router /users/me/devices/{token} [delete]
+// @Security BearerAuth
+func RemoveDeviceHandler(repo DeviceRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userObjID, ok := auth.CurrentUser(c)
+		if !ok {
+			return
+		}
+
+		err := repo.RemoveUserToken(c.Request.Context(), userObjID, c.Param("token"))
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "device not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"status": "removed"})
+	}
+}
+
+// GetSettingsHandler godoc
+// @Summary Get notification settings
+// @Description Get which daily notifications the current user receives
+// @Tags users
+// @Produce json
+// @Success 200 {object} Settings
+// @Router /users/me/notification-settings [get]
+// @Security BearerAuth
+func GetSettingsHandler(repo SettingsRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userObjID, ok := auth.CurrentUser(c)
+		if !ok {
+			return
+		}
+
+		settings, err := repo.GetSettings(c.Request.Context(), userObjID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, settings)
+	}
+}
+
+// UpdateSettingsHandler godoc
+// @Summary Update notification settings
+// @Description Opt in or out of daily reminders and digests
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param settings body Settings true "Settings"
+// @Success 200 {object} Settings
+// @Router /users/me/notification-settings [put]
+// @Security BearerAuth
+func UpdateSettingsHandler(repo SettingsRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userObjID, ok := auth.CurrentUser(c)
+		if !ok {
+			return
+		}
+
+		var settings Settings
+		if err := c.ShouldBindJSON(&settings); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		settings.UserID = userObjID
+
+		if err := repo.SetSettings(c.Request.Context(), settings); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, settings)
+	}
+}
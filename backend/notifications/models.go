@@ -0,0 +1,42 @@
+package notifications
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Device is a push token registered by one of a user's devices.
+type Device struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	UserID    primitive.ObjectID `bson:"userId"`
+	Token     string             `bson:"token"`
+	Platform  string             `bson:"platform"` // ios, android, web
+	CreatedAt time.Time          `bson:"createdAt"`
+}
+
+// Notification is a message for one user, before it is addressed to
+// their devices.
+type Notification struct {
+	UserID primitive.ObjectID
+	Title  string
+	Body   string
+	Data   map[string]string
+}
+
+// Message is a notification addressed to one device token.
+type Message struct {
+	To    string            `json:"to"`
+	Title string            `json:"title"`
+	Body  string            `json:"body"`
+	Data  map[string]string `json:"data,omitempty"`
+	Sound string            `json:"sound,omitempty"`
+}
+
+// Settings are a user's notification preferences. The zero value sends
+// everything.
+type Settings struct {
+	UserID        primitive.ObjectID `bson:"_id" json:"-"`
+	MuteReminders bool               `bson:"muteReminders" json:"muteReminders"` // no nudges to take the daily action
+	MuteDigest    bool               `bson:"muteDigest" json:"muteDigest"`       // no daily recap
+}
@@ -0,0 +1,124 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Provider delivers messages to devices. It returns the tokens the
+// service reported as no longer registered so they can be dropped.
+type Provider interface {
+	Send(ctx context.Context, msgs []Message) (invalid []string, err error)
+}
+
+const (
+	expoPushURL   = "https://exp.host/--/api/v2/push/send"
+	expoBatchSize = 100
+)
+
+// ExpoProvider sends through the Expo push API.
+type ExpoProvider struct {
+	URL         string
+	AccessToken string // optional, for projects with enhanced push security
+	Client      *http.Client
+}
+
+func NewExpoProvider(accessToken string) *ExpoProvider {
+	return &ExpoProvider{
+		URL:         expoPushURL,
+		AccessToken: accessToken,
+		Client:      &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type expoTicket struct {
+	Status  string `json:"status"` // ok, error
+	Message string `json:"message"`
+	Details struct {
+		Error string `json:"error"`
+	} `json:"details"`
+}
+
+func (p *ExpoProvider) Send(ctx context.Context, msgs []Message) ([]string, error) {
+	var invalid []string
+	for start := 0; start < len(msgs); start += expoBatchSize {
+		end := min(start+expoBatchSize, len(msgs))
+		batch := msgs[start:end]
+
+		body, err := json.Marshal(batch)
+		if err != nil {
+			return invalid, err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL, bytes.NewReader(body))
+		if err != nil {
+			return invalid, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		if p.AccessToken != "" {
+			req.Header.Set("Authorization", "Bearer "+p.AccessToken)
+		}
+
+		resp, err := p.Client.Do(req)
+		if err != nil {
+			return invalid, err
+		}
+		var result struct {
+			Data []expoTicket `json:"data"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return invalid, fmt.Errorf("expo push: unexpected status %d", resp.StatusCode)
+		}
+		if err != nil {
+			return invalid, fmt.Errorf("expo push: %w", err)
+		}
+
+		for i, ticket := range result.Data {
+			if i < len(batch) && ticket.Details.Error == "DeviceNotRegistered" {
+				invalid = append(invalid, batch[i].To)
+			}
+		}
+	}
+	return invalid, nil
+}
+
+// FakeProvider keeps messages in memory instead of sending them, for tests
+// and local development.
+type FakeProvider struct {
+	mu      sync.Mutex
+	Sent    []Message
+	Invalid map[string]bool // tokens to report as unregistered
+}
+
+func NewFakeProvider() *FakeProvider {
+	return &FakeProvider{Invalid: make(map[string]bool)}
+}
+
+func (p *FakeProvider) Send(ctx context.Context, msgs []Message) ([]string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var invalid []string
+	for _, m := range msgs {
+		if p.Invalid[m.To] {
+			invalid = append(invalid, m.To)
+			continue
+		}
+		p.Sent = append(p.Sent, m)
+	}
+	return invalid, nil
+}
+
+// Messages returns a copy of everything sent so far.
+func (p *FakeProvider) Messages() []Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Message(nil), p.Sent...)
+}
@@ -0,0 +1,71 @@
+package notifications
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type DeviceRepository interface {
+	AddDevice(ctx context.Context, d Device) error
+	FindByUser(ctx context.Context, userID primitive.ObjectID) ([]Device, error)
+	RemoveToken(ctx context.Context, token string) error
+	RemoveUserToken(ctx context.Context, userID primitive.ObjectID, token string) error
+	RemoveUser(ctx context.Context, userID primitive.ObjectID) error
+}
+
+type mongoRepository struct {
+	col *mongo.Collection
+}
+
+func NewMongoRepository(col *mongo.Collection) DeviceRepository {
+	return &mongoRepository{col: col}
+}
+
+// AddDevice stores a token, moving it to the new user if another account
+// registered it on the same device before.
+func (r *mongoRepository) AddDevice(ctx context.Context, d Device) error {
+	_, err := r.col.UpdateOne(
+		ctx,
+		bson.M{"token": d.Token},
+		bson.M{"$set": bson.M{"userId": d.UserID, "platform": d.Platform, "createdAt": d.CreatedAt}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+func (r *mongoRepository) FindByUser(ctx context.Context, userID primitive.ObjectID) ([]Device, error) {
+	var devices []Device
+	cursor, err := r.col.Find(ctx, bson.M{"userId": userID})
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(ctx, &devices); err != nil {
+		return nil, err
+	}
+	return devices, nil
+}
+
+func (r *mongoRepository) RemoveToken(ctx context.Context, token string) error {
+	_, err := r.col.DeleteOne(ctx, bson.M{"token": token})
+	return err
+}
+
+func (r *mongoRepository) RemoveUserToken(ctx context.Context, userID primitive.ObjectID, token string) error {
+	result, err := r.col.DeleteOne(ctx, bson.M{"userId": userID, "token": token})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *mongoRepository) RemoveUser(ctx context.Context, userID primitive.ObjectID) error {
+	_, err := r.col.DeleteMany(ctx, bson.M{"userId": userID})
+	return err
+}
@@ -0,0 +1,143 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"irl-mafia-game/game"
+	"log"
+	"time"
+)
+
+// Scheduler sends each game's daily reminder and digest once the game's
+// scheduled local time has passed.
+type Scheduler struct {
+	games      game.GameRepository
+	engine     *game.Engine
+	dispatcher *Dispatcher
+	settings   SettingsRepository
+	now        func() time.Time
+}
+
+func NewScheduler(games game.GameRepository, engine *game.Engine, dispatcher *Dispatcher, settings SettingsRepository) *Scheduler {
+	return &Scheduler{games: games, engine: engine, dispatcher: dispatcher, settings: settings, now: time.Now}
+}
+
+// Run checks every interval until ctx is done.
+func (s *Scheduler) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := s.Tick(ctx); err != nil {
+			log.Println("Failed to run daily notifications:", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick sends whatever is due. Each game is handled at most once per local
+// date, even across restarts or several server instances.
+func (s *Scheduler) Tick(ctx context.Context) error {
+	games, err := s.games.GetAllGames(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := s.now()
+	for _, g := range games {
+		if g.Status != "active" || !g.Started() {
+			continue
+		}
+		date, due := g.Schedule.Due(now)
+		if !due {
+			continue
+		}
+
+		// Catch up on lazy day processing first, so a points game that has
+		// run out of days ends instead of nagging its players
+		if err := game.Save(ctx, s.games, &g, s.engine.Advance); err != nil {
+			log.Println("Failed to advance game:", err)
+			continue
+		}
+		if g.Status != "active" {
+			continue
+		}
+
+		ok, err := s.settings.ClaimRun(ctx, g.ID.Hex()+":"+date)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		if err := s.dispatcher.Send(ctx, s.daily(ctx, g, now)); err != nil {
+			log.Println("Failed to send daily notifications:", err)
+		}
+	}
+	return nil
+}
+
+// daily builds a game's reminders and digests, leaving out anyone who
+// muted them.
+func (s *Scheduler) daily(ctx context.Context, g game.Game, now time.Time) []Notification {
+	today := g.Day(now)
+	standings := g.Standings()
+	rank := make(map[string]int, len(standings))
+	for i, st := range standings {
+		rank[st.UserID] = i
+	}
+
+	var digest string
+	if today > 0 {
+		digest = digestBody(g.PublicEvents(today - 1))
+	}
+
+	var notes []Notification
+	for _, p := range g.PlayerStates {
+		if p.Bot {
+			continue
+		}
+		settings, err := s.settings.GetSettings(ctx, p.User)
+		if err != nil {
+			log.Println("Failed to load notification settings:", err)
+			continue
+		}
+		if !settings.MuteReminders && !g.ActedToday(p, now) {
+			notes = append(notes, Notification{
+				UserID: p.User,
+				Title:  "Your move",
+				Body:   "You haven't claimed or guessed today.",
+				Data:   map[string]string{"gameId": g.ID.Hex(), "event": "reminder"},
+			})
+		}
+		if !settings.MuteDigest && digest != "" {
+			i := rank[p.User.Hex()]
+			notes = append(notes, Notification{
+				UserID: p.User,
+				Title:  fmt.Sprintf("Day %d recap", today),
+				Body:   fmt.Sprintf("%s You're #%d with %d points.", digest, i+1, standings[i].Score),
+				Data:   map[string]string{"gameId": g.ID.Hex(), "event": "digest"},
+			})
+		}
+	}
+	return notes
+}
+
+func digestBody(events []game.Event) string {
+	var claims, guesses, disputes int
+	for _, ev := range events {
+		switch ev.Type {
+		case game.EventClaim:
+			claims++
+		case game.EventGuess:
+			guesses++
+		case game.EventDisputeOpened:
+			disputes++
+		}
+	}
+	return fmt.Sprintf("Yesterday: %d claims, %d guesses, %d disputes.", claims, guesses, disputes)
+}
@@ -0,0 +1,56 @@
+package notifications
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type SettingsRepository interface {
+	GetSettings(ctx context.Context, userID primitive.ObjectID) (Settings, error)
+	SetSettings(ctx context.Context, s Settings) error
+	DeleteSettings(ctx context.Context, userID primitive.ObjectID) error
+	// ClaimRun records that a scheduled job ran for key, returning false
+	// if it already had.
+	ClaimRun(ctx context.Context, key string) (bool, error)
+}
+
+type mongoSettingsRepository struct {
+	settings *mongo.Collection
+	runs     *mongo.Collection
+}
+
+func NewMongoSettingsRepository(settings, runs *mongo.Collection) SettingsRepository {
+	return &mongoSettingsRepository{settings: settings, runs: runs}
+}
+
+func (r *mongoSettingsRepository) GetSettings(ctx context.Context, userID primitive.ObjectID) (Settings, error) {
+	s := Settings{UserID: userID}
+	err := r.settings.FindOne(ctx, bson.M{"_id": userID}).Decode(&s)
+	if err == mongo.ErrNoDocuments {
+		return s, nil
+	}
+	return s, err
+}
+
+func (r *mongoSettingsRepository) SetSettings(ctx context.Context, s Settings) error {
+	_, err := r.settings.ReplaceOne(ctx, bson.M{"_id": s.UserID}, s, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (r *mongoSettingsRepository) DeleteSettings(ctx context.Context, userID primitive.ObjectID) error {
+	_, err := r.settings.DeleteOne(ctx, bson.M{"_id": userID})
+	return err
+}
+
+func (r *mongoSettingsRepository) ClaimRun(ctx context.Context, key string) (bool, error) {
+	_, err := r.runs.InsertOne(ctx, bson.M{"_id": key, "createdAt": time.Now()})
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -0,0 +1,430 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"irl-mafia-game/auth"
+	"irl-mafia-game/user"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	stateTTL  = 10 * time.Minute
+	ticketTTL = 15 * time.Minute
+)
+
+// Login runs the authorization code flow against the configured providers.
+type Login struct {
+	providers  map[string]*Provider
+	returnURLs []string
+	states     StateRepository
+	users      user.UserRepository
+	tokens     *auth.Tokens
+}
+
+func NewLogin(cfg Config, states StateRepository, users user.UserRepository, tokens *auth.Tokens) *Login {
+	l := &Login{
+		providers:  make(map[string]*Provider),
+		returnURLs: cfg.ReturnURLs,
+		states:     states,
+		users:      users,
+		tokens:     tokens,
+	}
+	for _, p := range cfg.Providers {
+		l.providers[p.Name] = NewProvider(p, nil)
+	}
+	return l
+}
+
+// start records a login state and returns the provider URL to send the
+// user to.
+func (l *Login) start(ctx context.Context, p *Provider, linkUser primitive.ObjectID, returnTo string) (string, error) {
+	state, err := randomString()
+	if err != nil {
+		return "", err
+	}
+	nonce, err := randomString()
+	if err != nil {
+		return "", err
+	}
+	verifier, err := randomString()
+	if err != nil {
+		return "", err
+	}
+
+	err = l.states.AddState(ctx, LoginState{
+		State:     state,
+		Provider:  p.Name(),
+		Verifier:  verifier,
+		Nonce:     nonce,
+		LinkUser:  linkUser,
+		ReturnTo:  returnTo,
+		ExpiresAt: time.Now().Add(stateTTL),
+	})
+	if err != nil {
+		return "", err
+	}
+	return p.AuthURL(ctx, state, nonce, verifier)
+}
+
+func (l *Login) allowedReturn(u string) bool {
+	for _, allowed := range l.returnURLs {
+		if u == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+type SignupRequest struct {
+	Ticket   string `json:"ticket" binding:"required"`
+	Username string `json:"username" binding:"required"`
+}
+
+// GetProvidersHandler godoc
+// @Summary List login providers
+// @Description Names of the OpenID providers users can log in with
+// @Tags auth
+// @Produce json
+// @Success 200 {array} string
+// @Router /auth/oidc/providers [get]
+func GetProvidersHandler(l *Login) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		names := []string{}
+		for name := range l.providers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		c.JSON(http.StatusOK, names)
+	}
+}
+
+// StartLoginHandler godoc
+// @Summary Log in with a provider
+// @Description Redirect to the provider's login page. After logging in the callback responds with tokens or a signup ticket, or redirects to returnTo with them in the fragment.
+// @Tags auth
+// @Param provider path string true "Provider name"
+// @Param returnTo query string false "App URL to return to, must be configured"
+// @Success 302
+// @Router /auth/oidc/{provider}/login [get]
+func StartLoginHandler(l *Login) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p, ok := l.providers[c.Param("provider")]
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "unknown provider"})
+			return
+		}
+		returnTo := c.Query("returnTo")
+		if returnTo != "" && !l.allowedReturn(returnTo) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "returnTo is not allowed"})
+			return
+		}
+
+		authURL, err := l.start(c.Request.Context(), p, primitive.NilObjectID, returnTo)
+		if err != nil {
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+			return
+		}
+		c.Redirect(http.StatusFound, authURL)
+	}
+}
+
+// LinkIdentityHandler godoc
+// @Summary Link a provider to your account
+// @Description Get the provider URL to open. Once the user logs in there, the callback links that identity to the current account.
+// @Tags users
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Param returnTo query string false "App URL to return to, must be configured"
+// @Success 200 {object} map[string]string
+// @Router /users/me/identities/{provider} [post]
+// @Security BearerAuth
+func LinkIdentityHandler(l *Login) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userObjID, ok := auth.CurrentUser(c)
+		if !ok {
+			return
+		}
+		p, ok := l.providers[c.Param("provider")]
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "unknown provider"})
+			return
+		}
+		returnTo := c.Query("returnTo")
+		if returnTo != "" && !l.allowedReturn(returnTo) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "returnTo is not allowed"})
+			return
+		}
+
+		authURL, err := l.start(c.Request.Context(), p, userObjID, returnTo)
+		if err != nil {
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"url": authURL})
+	}
+}
+
+// CallbackHandler godoc
+// @Summary Finish logging in with a provider
+// @Description The provider redirects here. Known identities get tokens, new ones a signup ticket to pick a username with.
+// @Tags auth
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Param code query string true "Authorization code"
+// @Param state query string true "Login state"
+// @Success 200 {object} LoginResult
+// @Router /auth/oidc/{provider}/callback [get]
+func CallbackHandler(l *Login) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		if e := c.Query("error"); e != "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "login was not completed: " + e})
+			return
+		}
+
+		state, err := l.states.TakeState(ctx, c.Query("state"))
+		if err != nil || state.Provider != c.Param("provider") || time.Now().After(state.ExpiresAt) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired login state"})
+			return
+		}
+		p, ok := l.providers[state.Provider]
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "unknown provider"})
+			return
+		}
+
+		identity, err := p.Exchange(ctx, c.Query("code"), state.Verifier, state.Nonce)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+
+		result, status, err := l.complete(ctx, p.Name(), identity, state.LinkUser, auth.ClientFrom(c))
+		if err != nil {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+
+		if state.ReturnTo != "" {
+			c.Redirect(http.StatusFound, state.ReturnTo+"#"+result.fragment())
+			return
+		}
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// complete logs in, links or starts a signup for a verified identity.
+func (l *Login) complete(ctx context.Context, provider string, identity Identity, linkUser primitive.ObjectID, client auth.Client) (LoginResult, int, error) {
+	existing, err := l.users.FindUserWithIdentity(ctx, provider, identity.Subject)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return LoginResult{}, http.StatusInternalServerError, err
+	}
+	known := err == nil
+
+	if !linkUser.IsZero() {
+		if known && existing.ID != linkUser {
+			return LoginResult{}, http.StatusConflict, errors.New("this login is already linked to another account")
+		}
+		link := user.Identity{Provider: provider, Subject: identity.Subject, Email: identity.Email, LinkedAt: time.Now()}
+		err := l.users.AddIdentity(ctx, linkUser, link)
+		if mongo.IsDuplicateKeyError(err) {
+			return LoginResult{}, http.StatusConflict, errors.New("this login is already linked to another account")
+		}
+		if err != nil {
+			return LoginResult{}, http.StatusInternalServerError, err
+		}
+		return LoginResult{ID: linkUser.Hex(), Linked: provider}, http.StatusOK, nil
+	}
+
+	if known {
+		if existing.Disabled {
+			return LoginResult{}, http.StatusForbidden, errors.New("account disabled")
+		}
+		pair, err := l.tokens.Issue(ctx, existing.ID, client)
+		if err != nil {
+			return LoginResult{}, http.StatusInternalServerError, err
+		}
+		return LoginResult{
+			ID:           existing.ID.Hex(),
+			Username:     existing.Username,
+			Token:        pair.AccessToken,
+			RefreshToken: pair.RefreshToken,
+			ExpiresIn:    pair.ExpiresIn,
+		}, http.StatusOK, nil
+	}
+
+	id, err := randomString()
+	if err != nil {
+		return LoginResult{}, http.StatusInternalServerError, err
+	}
+	ticket := SignupTicket{
+		ID:        id,
+		Provider:  provider,
+		Subject:   identity.Subject,
+		Email:     identity.Email,
+		ExpiresAt: time.Now().Add(ticketTTL),
+	}
+	if err := l.states.AddTicket(ctx, ticket); err != nil {
+		return LoginResult{}, http.StatusInternalServerError, err
+	}
+	return LoginResult{SignupTicket: id, SuggestedUsername: identity.Username}, http.StatusOK, nil
+}
+
+// SignupHandler godoc
+// @Summary Create an account from a provider login
+// @Description Pick a username for an identity that has no account yet
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param signup body SignupRequest true "Signup ticket and username"
+// @Success 200 {object} LoginResult
+// @Router /auth/oidc/signup [post]
+func SignupHandler(l *Login) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		var req SignupRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if user.ReservedUsername(req.Username) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": user.ErrReservedUsername.Error()})
+			return
+		}
+		if _, err := l.users.FindUserWithUsername(ctx, req.Username); err == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "username already exists"})
+			return
+		}
+
+		ticket, err := l.states.TakeTicket(ctx, req.Ticket)
+		if err != nil || time.Now().After(ticket.ExpiresAt) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired signup ticket"})
+			return
+		}
+
+		// The account has no password anyone knows; one can be set later
+		// through a password reset
+		password, err := randomString()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		u := user.User{
+			Username: req.Username,
+			Password: password,
+			Email:    ticket.Email,
+			Identities: []user.Identity{
+				{Provider: ticket.Provider, Subject: ticket.Subject, Email: ticket.Email, LinkedAt: time.Now()},
+			},
+		}
+		err = l.users.AddUser(ctx, u)
+		if mongo.IsDuplicateKeyError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "username, email or login already in use"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		created, err := l.users.FindUserWithUsername(ctx, req.Username)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		pair, err := l.tokens.Issue(ctx, created.ID, auth.ClientFrom(c))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
+			return
+		}
+		c.JSON(http.StatusOK, LoginResult{
+			ID:           created.ID.Hex(),
+			Username:     created.Username,
+			Token:        pair.AccessToken,
+			RefreshToken: pair.RefreshToken,
+			ExpiresIn:    pair.ExpiresIn,
+		})
+	}
+}
+
+// GetIdentitiesHandler godoc
+// @Summary List linked logins
+// @Description Get the external providers linked to the current account
+// @Tags users
+// @Produce json
+// @Success 200 {array} user.Identity
+// @Router /users/me/identities [get]
+// @Security BearerAuth
+func GetIdentitiesHandler(users user.UserRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userObjID, ok := auth.CurrentUser(c)
+		if !ok {
+			return
+		}
+		u, err := users.FindUserWithID(c.Request.Context(), userObjID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		identities := u.Identities
+		if identities == nil {
+			identities = []user.Identity{}
+		}
+		c.JSON(http.StatusOK, identities)
+	}
+}
+
+// UnlinkIdentityHandler godoc
+// @Summary Unlink a provider
+// @Description Stop allowing logins to the current account through a provider
+// @Tags users
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Success 200 {object} map[string]string
+// @Router /users/me/identities/{provider} [delete]
+// @Security BearerAuth
+func UnlinkIdentityHandler(users user.UserRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userObjID, ok := auth.CurrentUser(c)
+		if !ok {
+			return
+		}
+		err := users.RemoveIdentity(c.Request.Context(), userObjID, c.Param("provider"))
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "identity not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "unlinked"})
+	}
+}
+
+// fragment encodes a result for the app's return URL.
+func (r LoginResult) fragment() string {
+	v := url.Values{}
+	set := func(k, val string) {
+		if val != "" {
+			v.Set(k, val)
+		}
+	}
+	set("id", r.ID)
+	set("username", r.Username)
+	set("token", r.Token)
+	set("refreshToken", r.RefreshToken)
+	set("signupTicket", r.SignupTicket)
+	set("suggestedUsername", r.SuggestedUsername)
+	set("linked", r.Linked)
+	return v.Encode()
+}
@@ -0,0 +1,254 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"irl-mafia-game/user"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type memStates struct {
+	mu      sync.Mutex
+	states  map[string]LoginState
+	tickets map[string]SignupTicket
+}
+
+func newMemStates() *memStates {
+	return &memStates{states: make(map[string]LoginState), tickets: make(map[string]SignupTicket)}
+}
+
+func (m *memStates) AddState(ctx context.Context, s LoginState) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.states[s.State] = s
+	return nil
+}
+
+func (m *memStates) TakeState(ctx context.Context, state string) (LoginState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.states[state]
+	if !ok {
+		return LoginState{}, mongo.ErrNoDocuments
+	}
+	delete(m.states, state)
+	return s, nil
+}
+
+func (m *memStates) AddTicket(ctx context.Context, t SignupTicket) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tickets[t.ID] = t
+	return nil
+}
+
+func (m *memStates) TakeTicket(ctx context.Context, id string) (SignupTicket, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.tickets[id]
+	if !ok {
+		return SignupTicket{}, mongo.ErrNoDocuments
+	}
+	delete(m.tickets, id)
+	return t, nil
+}
+
+// memUsers keeps just the linked identities of each account; the rest of
+// the repository is not used by the callback.
+type memUsers struct {
+	user.UserRepository
+	identities map[primitive.ObjectID][]user.Identity
+}
+
+func (m *memUsers) FindUserWithIdentity(ctx context.Context, provider, subject string) (user.User, error) {
+	for id, identities := range m.identities {
+		for _, i := range identities {
+			if i.Provider == provider && i.Subject == subject {
+				return user.User{ID: id, Identities: identities}, nil
+			}
+		}
+	}
+	return user.User{}, mongo.ErrNoDocuments
+}
+
+func (m *memUsers) AddIdentity(ctx context.Context, userID primitive.ObjectID, identity user.Identity) error {
+	kept := []user.Identity{}
+	for _, i := range m.identities[userID] {
+		if i.Provider != identity.Provider {
+			kept = append(kept, i)
+		}
+	}
+	m.identities[userID] = append(kept, identity)
+	return nil
+}
+
+type callbackTest struct {
+	login  *Login
+	states *memStates
+	users  *memUsers
+	router *gin.Engine
+}
+
+func newCallbackTest(t *testing.T) *callbackTest {
+	gin.SetMode(gin.TestMode)
+	ti := newTestIssuer(t)
+	ct := &callbackTest{
+		states: newMemStates(),
+		users:  &memUsers{identities: make(map[primitive.ObjectID][]user.Identity)},
+		router: gin.New(),
+	}
+	ct.login = NewLogin(Config{Providers: []ProviderConfig{ti.config()}}, ct.states, ct.users, nil)
+	ct.router.GET("/auth/oidc/:provider/callback", CallbackHandler(ct.login))
+	return ct
+}
+
+// begin starts a login (or a link, for a non-zero linkUser) and logs in
+// at the provider as subject.
+func (ct *callbackTest) begin(t *testing.T, linkUser primitive.ObjectID, subject string) (code, state string) {
+	t.Helper()
+	authURL, err := ct.login.start(context.Background(), ct.login.providers["test"], linkUser, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return authorize(t, authURL, subject)
+}
+
+func (ct *callbackTest) callback(provider, code, state string) (*httptest.ResponseRecorder, LoginResult) {
+	q := url.Values{"code": {code}, "state": {state}}
+	req := httptest.NewRequest(http.MethodGet, "/auth/oidc/"+provider+"/callback?"+q.Encode(), nil)
+	w := httptest.NewRecorder()
+	ct.router.ServeHTTP(w, req)
+	var result LoginResult
+	json.Unmarshal(w.Body.Bytes(), &result)
+	return w, result
+}
+
+func TestCallbackState(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(ct *callbackTest, state string) (provider, useState string)
+	}{
+		{name: "unknown state", setup: func(ct *callbackTest, state string) (string, string) {
+			return "test", "forged"
+		}},
+		{name: "state for another provider", setup: func(ct *callbackTest, state string) (string, string) {
+			return "other", state
+		}},
+		{name: "expired state", setup: func(ct *callbackTest, state string) (string, string) {
+			s := ct.states.states[state]
+			s.ExpiresAt = time.Now().Add(-time.Second)
+			ct.states.states[state] = s
+			return "test", state
+		}},
+		{name: "replayed state", setup: func(ct *callbackTest, state string) (string, string) {
+			ct.states.TakeState(context.Background(), state)
+			return "test", state
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ct := newCallbackTest(t)
+			code, state := ct.begin(t, primitive.NilObjectID, "bob")
+			provider, useState := tt.setup(ct, state)
+
+			w, _ := ct.callback(provider, code, useState)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400: %s", w.Code, w.Body)
+			}
+		})
+	}
+}
+
+func TestCallbackPKCEVerifier(t *testing.T) {
+	ct := newCallbackTest(t)
+	code, state := ct.begin(t, primitive.NilObjectID, "bob")
+	s := ct.states.states[state]
+	s.Verifier = "not-the-verifier"
+	ct.states.states[state] = s
+
+	w, _ := ct.callback("test", code, state)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want 401: %s", w.Code, w.Body)
+	}
+}
+
+func TestCallbackNonceMismatch(t *testing.T) {
+	ct := newCallbackTest(t)
+	code, state := ct.begin(t, primitive.NilObjectID, "bob")
+	s := ct.states.states[state]
+	s.Nonce = "not-the-nonce"
+	ct.states.states[state] = s
+
+	w, _ := ct.callback("test", code, state)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want 401: %s", w.Code, w.Body)
+	}
+}
+
+func TestCallbackNewIdentity(t *testing.T) {
+	ct := newCallbackTest(t)
+	code, state := ct.begin(t, primitive.NilObjectID, "bob")
+
+	w, result := ct.callback("test", code, state)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+	if result.SuggestedUsername != "bob" || result.Token != "" {
+		t.Errorf("result = %+v, want a signup ticket for bob", result)
+	}
+	ticket, ok := ct.states.tickets[result.SignupTicket]
+	if !ok || ticket.Provider != "test" || ticket.Subject != "bob" || ticket.Email != "bob@example.com" {
+		t.Errorf("ticket = %+v", ticket)
+	}
+}
+
+func TestCallbackLink(t *testing.T) {
+	alice := primitive.NewObjectID()
+	other := primitive.NewObjectID()
+
+	tests := []struct {
+		name       string
+		linkedTo   primitive.ObjectID // who already has test/bob, if anyone
+		wantStatus int
+	}{
+		{name: "new identity", wantStatus: http.StatusOK},
+		{name: "relink own identity", linkedTo: alice, wantStatus: http.StatusOK},
+		{name: "identity of another account", linkedTo: other, wantStatus: http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ct := newCallbackTest(t)
+			if !tt.linkedTo.IsZero() {
+				ct.users.identities[tt.linkedTo] = []user.Identity{{Provider: "test", Subject: "bob"}}
+			}
+			code, state := ct.begin(t, alice, "bob")
+
+			w, result := ct.callback("test", code, state)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				if len(ct.users.identities[alice]) != 0 {
+					t.Errorf("alice got identities %+v", ct.users.identities[alice])
+				}
+				return
+			}
+			if result.Linked != "test" || result.ID != alice.Hex() || result.Token != "" {
+				t.Errorf("result = %+v", result)
+			}
+			linked := ct.users.identities[alice]
+			if len(linked) != 1 || linked[0].Subject != "bob" || linked[0].Email != "bob@example.com" {
+				t.Errorf("alice's identities = %+v", linked)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package oidc
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// LoginState remembers a login in progress between sending the user to
+// the provider and the provider sending them back.
+type LoginState struct {
+	State     string             `bson:"_id"`
+	Provider  string             `bson:"provider"`
+	Verifier  string             `bson:"verifier"` // PKCE code verifier
+	Nonce     string             `bson:"nonce"`
+	LinkUser  primitive.ObjectID `bson:"linkUser,omitempty"` // set when linking to a logged-in account
+	ReturnTo  string             `bson:"returnTo,omitempty"`
+	ExpiresAt time.Time          `bson:"expiresAt"`
+}
+
+// SignupTicket holds a verified identity with no account yet, until the
+// user picks a username.
+type SignupTicket struct {
+	ID        string    `bson:"_id"`
+	Provider  string    `bson:"provider"`
+	Subject   string    `bson:"subject"`
+	Email     string    `bson:"email,omitempty"`
+	ExpiresAt time.Time `bson:"expiresAt"`
+}
+
+// LoginResult is the outcome of a callback: tokens for a known identity,
+// or a ticket to finish signing up with.
+type LoginResult struct {
+	ID                string `json:"id,omitempty"`
+	Username          string `json:"username,omitempty"`
+	Token             string `json:"token,omitempty"`
+	RefreshToken      string `json:"refreshToken,omitempty"`
+	ExpiresIn         int    `json:"expiresIn,omitempty"`
+	SignupTicket      string `json:"signupTicket,omitempty"`
+	SuggestedUsername string `json:"suggestedUsername,omitempty"`
+	Linked            string `json:"linked,omitempty"` // provider just linked to the current account
+}
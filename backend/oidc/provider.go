@@ -0,0 +1,337 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ProviderConfig describes one identity provider in the JSON file named
+// by OIDC_PROVIDERS_FILE.
+type ProviderConfig struct {
+	Name         string   `json:"name"` // used in URLs, e.g. google
+	Issuer       string   `json:"issuer"`
+	ClientID     string   `json:"clientId"`
+	ClientSecret string   `json:"clientSecret"` // optional for public clients
+	RedirectURL  string   `json:"redirectUrl"`  // our callback, /auth/oidc/{name}/callback
+	Scopes       []string `json:"scopes"`       // defaults to openid email profile
+}
+
+type Config struct {
+	Providers  []ProviderConfig `json:"providers"`
+	ReturnURLs []string         `json:"returnUrls"` // where the app may ask to be sent after login
+}
+
+// LoadConfig reads OIDC_PROVIDERS_FILE. Without it no providers are
+// configured.
+func LoadConfig() (Config, error) {
+	var cfg Config
+	path := os.Getenv("OIDC_PROVIDERS_FILE")
+	if path == "" {
+		return cfg, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("invalid OIDC config: %w", err)
+	}
+	return cfg, nil
+}
+
+// Identity is what a provider vouched for in a verified ID token.
+type Identity struct {
+	Subject  string
+	Email    string
+	Username string // preferred_username, or a guess from email and name
+}
+
+// Provider talks to one OpenID provider. Its discovery document and keys
+// are fetched on first use and the keys refetched when an unknown kid
+// shows up.
+type Provider struct {
+	cfg    ProviderConfig
+	client *http.Client
+
+	mu        sync.Mutex
+	discovery *discovery
+	keys      map[string]any
+}
+
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+func NewProvider(cfg ProviderConfig, client *http.Client) *Provider {
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "email", "profile"}
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Provider{cfg: cfg, client: client}
+}
+
+func (p *Provider) Name() string {
+	return p.cfg.Name
+}
+
+// AuthURL is where to send the user to log in, with a PKCE challenge for
+// verifier.
+func (p *Provider) AuthURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	d, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	challenge := sha256.Sum256([]byte(verifier))
+	q := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.cfg.ClientID},
+		"redirect_uri":          {p.cfg.RedirectURL},
+		"scope":                 {strings.Join(p.cfg.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+	sep := "?"
+	if strings.Contains(d.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return d.AuthorizationEndpoint + sep + q.Encode(), nil
+}
+
+// Exchange redeems an authorization code and verifies the ID token that
+// comes back.
+func (p *Provider) Exchange(ctx context.Context, code, verifier, nonce string) (Identity, error) {
+	d, err := p.discover(ctx)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.cfg.RedirectURL},
+		"client_id":     {p.cfg.ClientID},
+		"code_verifier": {verifier},
+	}
+	if p.cfg.ClientSecret != "" {
+		form.Set("client_secret", p.cfg.ClientSecret)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Identity{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return Identity{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Identity{}, fmt.Errorf("token endpoint returned %s", resp.Status)
+	}
+	var tokens struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokens); err != nil {
+		return Identity{}, err
+	}
+	if tokens.IDToken == "" {
+		return Identity{}, errors.New("no ID token in token response")
+	}
+	return p.verify(ctx, tokens.IDToken, nonce)
+}
+
+func (p *Provider) verify(ctx context.Context, idToken, nonce string) (Identity, error) {
+	d, err := p.discover(ctx)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(idToken, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.key(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "ES256", "EdDSA"}),
+		jwt.WithIssuer(d.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return Identity{}, fmt.Errorf("invalid ID token: %w", err)
+	}
+	if got, _ := claims["nonce"].(string); got != nonce {
+		return Identity{}, errors.New("invalid ID token: nonce mismatch")
+	}
+
+	id := Identity{}
+	id.Subject, _ = claims["sub"].(string)
+	if id.Subject == "" {
+		return Identity{}, errors.New("invalid ID token: no subject")
+	}
+	if verified, _ := claims["email_verified"].(bool); verified {
+		id.Email, _ = claims["email"].(string)
+	}
+	id.Username, _ = claims["preferred_username"].(string)
+	if id.Username == "" {
+		if email, _ := claims["email"].(string); email != "" {
+			id.Username, _, _ = strings.Cut(email, "@")
+		} else {
+			id.Username, _ = claims["name"].(string)
+		}
+	}
+	return id, nil
+}
+
+func (p *Provider) discover(ctx context.Context) (*discovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	var d discovery
+	if err := p.getJSON(ctx, strings.TrimSuffix(p.cfg.Issuer, "/")+"/.well-known/openid-configuration", &d); err != nil {
+		return nil, fmt.Errorf("OIDC discovery failed: %w", err)
+	}
+	if d.Issuer != p.cfg.Issuer {
+		return nil, errors.New("OIDC discovery returned a different issuer")
+	}
+	p.discovery = &d
+	return p.discovery, nil
+}
+
+func (p *Provider) key(ctx context.Context, kid string) (any, error) {
+	p.mu.Lock()
+	k, ok := p.keys[kid]
+	jwksURI := ""
+	if p.discovery != nil {
+		jwksURI = p.discovery.JWKSURI
+	}
+	p.mu.Unlock()
+	if ok {
+		return k, nil
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := p.getJSON(ctx, jwksURI, &set); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]any)
+	for _, j := range set.Keys {
+		if pub, err := j.publicKey(); err == nil {
+			keys[j.Kid] = pub
+		}
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	p.mu.Unlock()
+	if k, ok := keys[kid]; ok {
+		return k, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+func (p *Provider) getJSON(ctx context.Context, u string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", u, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (j jwk) publicKey() (any, error) {
+	dec := base64.RawURLEncoding.DecodeString
+	switch j.Kty {
+	case "RSA":
+		n, err := dec(j.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := dec(j.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		if j.Crv != "P-256" {
+			return nil, errors.New("unsupported curve")
+		}
+		x, err := dec(j.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := dec(j.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	case "OKP":
+		if j.Crv != "Ed25519" {
+			return nil, errors.New("unsupported curve")
+		}
+		x, err := dec(j.X)
+		if err != nil {
+			return nil, err
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, errors.New("unsupported key type")
+}
+
+// randomString returns a URL-safe random string, used for state, nonce
+// and PKCE verifiers.
+func randomString() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,160 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"irl-mafia-game/oidc/oidctest"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testClientID = "mafia"
+
+// testIssuer runs an oidctest server and counts how often its keys are
+// fetched.
+type testIssuer struct {
+	*httptest.Server
+	jwksHits atomic.Int32
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	t.Helper()
+	srv, err := oidctest.NewServer("", testClientID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ti := &testIssuer{}
+	ti.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/jwks" {
+			ti.jwksHits.Add(1)
+		}
+		srv.ServeHTTP(w, r)
+	}))
+	srv.Issuer = ti.URL
+	t.Cleanup(ti.Close)
+	return ti
+}
+
+func (ti *testIssuer) config() ProviderConfig {
+	return ProviderConfig{
+		Name:        "test",
+		Issuer:      ti.URL,
+		ClientID:    testClientID,
+		RedirectURL: "http://app.test/callback",
+	}
+}
+
+// authorize logs in as subject at the provider and returns the code and
+// state it redirects back with.
+func authorize(t *testing.T, authURL, subject string) (code, state string) {
+	t.Helper()
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(authURL + "&login_hint=" + url.QueryEscape(subject))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("authorize returned %s", resp.Status)
+	}
+	loc, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loc.Query().Get("code"), loc.Query().Get("state")
+}
+
+func TestExchange(t *testing.T) {
+	ti := newTestIssuer(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		verifier string
+		nonce    string
+		wantErr  string
+	}{
+		{name: "valid", verifier: "verifier", nonce: "nonce"},
+		{name: "wrong PKCE verifier", verifier: "other-verifier", nonce: "nonce", wantErr: "400"},
+		{name: "nonce mismatch", verifier: "verifier", nonce: "other-nonce", wantErr: "nonce mismatch"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewProvider(ti.config(), nil)
+			authURL, err := p.AuthURL(ctx, "state", "nonce", "verifier")
+			if err != nil {
+				t.Fatal(err)
+			}
+			code, state := authorize(t, authURL, "bob")
+			if state != "state" {
+				t.Fatalf("state = %q, want it passed through", state)
+			}
+
+			id, err := p.Exchange(ctx, code, tt.verifier, tt.nonce)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if id.Subject != "bob" || id.Email != "bob@example.com" || id.Username != "bob" {
+				t.Errorf("identity = %+v", id)
+			}
+		})
+	}
+}
+
+func TestVerifyUnknownKid(t *testing.T) {
+	ti := newTestIssuer(t)
+	ctx := context.Background()
+	p := NewProvider(ti.config(), nil)
+
+	// Cache the provider's keys with a normal login first
+	authURL, err := p.AuthURL(ctx, "state", "nonce", "verifier")
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, _ := authorize(t, authURL, "bob")
+	if _, err := p.Exchange(ctx, code, "verifier", "nonce"); err != nil {
+		t.Fatal(err)
+	}
+	hits := ti.jwksHits.Load()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   ti.URL,
+		"aud":   testClientID,
+		"sub":   "mallory",
+		"nonce": "nonce",
+		"exp":   time.Now().Add(time.Minute).Unix(),
+	})
+	token.Header["kid"] = "rotated"
+	idToken, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = p.verify(ctx, idToken, "nonce")
+	if err == nil || !strings.Contains(err.Error(), `unknown signing key "rotated"`) {
+		t.Fatalf("err = %v, want unknown signing key", err)
+	}
+	if got := ti.jwksHits.Load(); got != hits+1 {
+		t.Errorf("keys fetched %d more times, want 1 refetch for the unknown kid", got-hits)
+	}
+}
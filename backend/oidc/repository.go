@@ -0,0 +1,47 @@
+package oidc
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type StateRepository interface {
+	AddState(ctx context.Context, s LoginState) error
+	// TakeState returns a login state and deletes it, so it works once.
+	TakeState(ctx context.Context, state string) (LoginState, error)
+	AddTicket(ctx context.Context, t SignupTicket) error
+	TakeTicket(ctx context.Context, id string) (SignupTicket, error)
+}
+
+type mongoRepository struct {
+	states  *mongo.Collection
+	tickets *mongo.Collection
+}
+
+func NewMongoRepository(states, tickets *mongo.Collection) StateRepository {
+	return &mongoRepository{states: states, tickets: tickets}
+}
+
+func (r *mongoRepository) AddState(ctx context.Context, s LoginState) error {
+	_, err := r.states.InsertOne(ctx, s)
+	return err
+}
+
+func (r *mongoRepository) TakeState(ctx context.Context, state string) (LoginState, error) {
+	var s LoginState
+	err := r.states.FindOneAndDelete(ctx, bson.M{"_id": state}).Decode(&s)
+	return s, err
+}
+
+func (r *mongoRepository) AddTicket(ctx context.Context, t SignupTicket) error {
+	_, err := r.tickets.InsertOne(ctx, t)
+	return err
+}
+
+func (r *mongoRepository) TakeTicket(ctx context.Context, id string) (SignupTicket, error) {
+	var t SignupTicket
+	err := r.tickets.FindOneAndDelete(ctx, bson.M{"_id": id}).Decode(&t)
+	return t, err
+}
@@ -0,0 +1,215 @@
+package user
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	AvatarSize      = 256
+	AvatarThumbSize = 64
+
+	maxAvatarBytes     = 5 << 20
+	maxAvatarDimension = 4096
+)
+
+var (
+	ErrAvatarTooLarge  = errors.New("avatar must be at most 5 MB and 4096x4096 pixels")
+	ErrAvatarType      = errors.New("avatar must be a JPEG, PNG or GIF image")
+	ErrAvatarNotFound  = errors.New("avatar not found")
+	avatarContentTypes = map[string]bool{"image/jpeg": true, "image/png": true, "image/gif": true}
+)
+
+// AvatarStore keeps avatar images by file name.
+type AvatarStore interface {
+	Save(ctx context.Context, name string, data []byte) error
+	Open(ctx context.Context, name string) (io.ReadCloser, error)
+	Delete(ctx context.Context, name string) error
+}
+
+// AvatarURL is where an avatar image of the given size is served.
+func AvatarURL(key string, size int) string {
+	return "/avatars/" + avatarFile(key, size)
+}
+
+func avatarFile(key string, size int) string {
+	return fmt.Sprintf("%s-%d.jpg", key, size)
+}
+
+// ProcessAvatar checks an upload and renders it as square JPEGs at the
+// full and thumbnail sizes.
+func ProcessAvatar(data []byte) (map[int][]byte, error) {
+	if len(data) > maxAvatarBytes {
+		return nil, ErrAvatarTooLarge
+	}
+	if !avatarContentTypes[http.DetectContentType(data)] {
+		return nil, ErrAvatarType
+	}
+
+	// Check the dimensions before decoding so a small file can't claim a
+	// huge canvas
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrAvatarType
+	}
+	if cfg.Width > maxAvatarDimension || cfg.Height > maxAvatarDimension {
+		return nil, ErrAvatarTooLarge
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrAvatarType
+	}
+
+	out := make(map[int][]byte)
+	for _, size := range []int{AvatarSize, AvatarThumbSize} {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, squareThumbnail(img, size), &jpeg.Options{Quality: 85}); err != nil {
+			return nil, err
+		}
+		out[size] = buf.Bytes()
+	}
+	return out, nil
+}
+
+// squareThumbnail crops the centre square of src and scales it to size
+// pixels with a box filter. Transparent areas become white.
+func squareThumbnail(src image.Image, size int) image.Image {
+	b := src.Bounds()
+	side := min(b.Dx(), b.Dy())
+	crop := image.Rect(0, 0, side, side).Add(image.Pt(b.Min.X+(b.Dx()-side)/2, b.Min.Y+(b.Dy()-side)/2))
+
+	flat := image.NewRGBA(image.Rect(0, 0, side, side))
+	draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), src, crop.Min, draw.Over)
+
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		y0, y1 := y*side/size, max((y+1)*side/size, y*side/size+1)
+		for x := 0; x < size; x++ {
+			x0, x1 := x*side/size, max((x+1)*side/size, x*side/size+1)
+			var r, g, bl, n uint32
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					i := flat.PixOffset(sx, sy)
+					r += uint32(flat.Pix[i])
+					g += uint32(flat.Pix[i+1])
+					bl += uint32(flat.Pix[i+2])
+					n++
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{uint8(r / n), uint8(g / n), uint8(bl / n), 255})
+		}
+	}
+	return dst
+}
+
+// NewAvatarKey returns a fresh key, so a changed avatar gets new URLs
+// and can be cached forever.
+func NewAvatarKey(userID string) (string, error) {
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return userID + "-" + hex.EncodeToString(buf), nil
+}
+
+// DiskAvatarStore keeps avatars as files in a directory.
+type DiskAvatarStore struct {
+	dir string
+}
+
+func NewDiskAvatarStore(dir string) (*DiskAvatarStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &DiskAvatarStore{dir: dir}, nil
+}
+
+func (s *DiskAvatarStore) Save(ctx context.Context, name string, data []byte) error {
+	return os.WriteFile(filepath.Join(s.dir, filepath.Base(name)), data, 0o644)
+}
+
+func (s *DiskAvatarStore) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	f, err := os.Open(filepath.Join(s.dir, filepath.Base(name)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrAvatarNotFound
+	}
+	return f, err
+}
+
+func (s *DiskAvatarStore) Delete(ctx context.Context, name string) error {
+	err := os.Remove(filepath.Join(s.dir, filepath.Base(name)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// GridFSAvatarStore keeps avatars in a GridFS bucket, for servers without
+// persistent disks.
+type GridFSAvatarStore struct {
+	bucket *gridfs.Bucket
+}
+
+func NewGridFSAvatarStore(db *mongo.Database) (*GridFSAvatarStore, error) {
+	bucket, err := gridfs.NewBucket(db, options.GridFSBucket().SetName("avatars"))
+	if err != nil {
+		return nil, err
+	}
+	return &GridFSAvatarStore{bucket: bucket}, nil
+}
+
+func (s *GridFSAvatarStore) Save(ctx context.Context, name string, data []byte) error {
+	_, err := s.bucket.UploadFromStream(name, bytes.NewReader(data))
+	return err
+}
+
+func (s *GridFSAvatarStore) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	stream, err := s.bucket.OpenDownloadStreamByName(name)
+	if err == gridfs.ErrFileNotFound {
+		return nil, ErrAvatarNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
+func (s *GridFSAvatarStore) Delete(ctx context.Context, name string) error {
+	cursor, err := s.bucket.Find(bson.M{"filename": name})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var file struct {
+			ID any `bson:"_id"`
+		}
+		if err := cursor.Decode(&file); err != nil {
+			return err
+		}
+		if err := s.bucket.Delete(file.ID); err != nil && err != gridfs.ErrFileNotFound {
+			return err
+		}
+	}
+	return cursor.Err()
+}
@@ -0,0 +1,52 @@
+package user
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+)
+
+// GuestPrefix starts every guest's generated username, and can't be used
+// to sign up.
+const GuestPrefix = "guest-"
+
+// GuestTTL is how long a guest can go without playing before the account
+// is removed.
+const GuestTTL = 30 * 24 * time.Hour
+
+var ErrReservedUsername = errors.New("usernames starting with " + GuestPrefix + " are reserved")
+
+type UpgradeRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+	Email    string `json:"email"`
+}
+
+// NewGuest returns a guest account shown to other players under name. It
+// gets a random password nobody knows, so it can only be used through its
+// tokens until it is upgraded.
+func NewGuest(name string) (User, error) {
+	id := make([]byte, 6)
+	if _, err := rand.Read(id); err != nil {
+		return User{}, err
+	}
+	password := make([]byte, 32)
+	if _, err := rand.Read(password); err != nil {
+		return User{}, err
+	}
+	return User{
+		Username: GuestPrefix + hex.EncodeToString(id),
+		Password: base64.RawURLEncoding.EncodeToString(password),
+		Guest:    true,
+		LastSeen: time.Now(),
+		Profile:  Profile{DisplayName: strings.TrimSpace(name)},
+	}, nil
+}
+
+// ReservedUsername reports whether a username is kept for guests.
+func ReservedUsername(username string) bool {
+	return strings.HasPrefix(strings.ToLower(username), GuestPrefix)
+}
@@ -2,11 +2,20 @@
 package user
 
 import (
+	"context"
+	"errors"
+	"io"
 	"irl-mafia-game/auth"
+	"irl-mafia-game/mail"
+	"log"
 	"net/http"
+	netmail "net/mail"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -14,6 +23,7 @@ import (
 type SignupRequest struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
+	Email    string `json:"email"` // optional, needed to reset a forgotten password
 }
 
 type LoginRequest struct {
@@ -21,10 +31,33 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
-type LoginResponse struct {
-	ID       string `json:"id"`
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword" binding:"required"`
+	NewPassword     string `json:"newPassword" binding:"required"`
+}
+
+type ForgotPasswordRequest struct {
 	Username string `json:"username"`
-	Token    string `json:"token"`
+	Email    string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type UpdateProfileRequest struct {
+	DisplayName string `json:"displayName"`
+	Bio         string `json:"bio"`
+	Pronouns    string `json:"pronouns"` // e.g. she/her, they/them
+}
+
+type LoginResponse struct {
+	ID           string `json:"id"`
+	Username     string `json:"username"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+	ExpiresIn    int    `json:"expiresIn"` // access token lifetime in seconds
 }
 
 // SignupHandler godoc
@@ -44,14 +77,29 @@ func SignupHandler(repo UserRepository) gin.HandlerFunc {
 			return
 		}
 
+		if ReservedUsername(req.Username) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": ErrReservedUsername.Error()})
+			return
+		}
+		if err := validPassword(req.Password); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		email, err := normalizeEmail(req.Email)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
 		user := User{
 			Username: req.Username,
 			Password: req.Password,
+			Email:    email,
 		}
 
-		err := repo.AddUser(c.Request.Context(), user)
+		err = repo.AddUser(c.Request.Context(), user)
 		if mongo.IsDuplicateKeyError(err) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "username already exists"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": "username or email already exists"})
 			return
 		}
 		if err != nil {
@@ -65,14 +113,14 @@ func SignupHandler(repo UserRepository) gin.HandlerFunc {
 
 // LoginHandler godoc
 // @Summary Login a user
-// @Description Authenticate user and return JWT token
+// @Description Authenticate user and return a short-lived JWT access token and a refresh token
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param user body LoginRequest true "User info"
 // @Success 200 {object} LoginResponse
 // @Router /login [post]
-func LoginHandler(repo UserRepository) gin.HandlerFunc {
+func LoginHandler(repo UserRepository, tokens *auth.Tokens, guard *auth.LoginGuard) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var req LoginRequest
 		if err := c.BindJSON(&req); err != nil {
@@ -80,85 +128,525 @@ func LoginHandler(repo UserRepository) gin.HandlerFunc {
 			return
 		}
 
+		// Repeated failures slow down and then lock out further attempts
+		wait, err := guard.Attempt(c.Request.Context(), req.Username, c.ClientIP())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if wait > 0 {
+			if err := guard.Fail(c.Request.Context(), req.Username, c.ClientIP(), c.Request.UserAgent(), "locked"); err != nil {
+				log.Println("Failed to audit login:", err)
+			}
+			seconds := int(wait.Round(time.Second) / time.Second)
+			c.Header("Retry-After", strconv.Itoa(max(seconds, 1)))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "too many failed logins, try again later"})
+			return
+		}
+
 		user, err := repo.FindUserWithUsername(c.Request.Context(), req.Username)
 		if err != nil || bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)) != nil {
+			if err := guard.Fail(c.Request.Context(), req.Username, c.ClientIP(), c.Request.UserAgent(), "invalid_credentials"); err != nil {
+				log.Println("Failed to record failed login:", err)
+			}
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
 			return
 		}
+		if err := guard.Succeed(c.Request.Context(), req.Username, c.ClientIP()); err != nil {
+			log.Println("Failed to reset login attempts:", err)
+		}
+		if user.Disabled {
+			c.JSON(http.StatusForbidden, gin.H{"error": "account disabled"})
+			return
+		}
 
-		token, err := auth.GenerateToken(user.ID.Hex())
+		pair, err := tokens.Issue(c.Request.Context(), user.ID, auth.ClientFrom(c))
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
 			return
 		}
 
 		c.JSON(http.StatusOK, LoginResponse{
+			ID:           user.ID.Hex(),
+			Username:     user.Username,
+			Token:        pair.AccessToken,
+			RefreshToken: pair.RefreshToken,
+			ExpiresIn:    pair.ExpiresIn,
+		})
+	}
+}
+
+// GetCurrentUserHandler godoc
+// @Summary Get current user
+// @Description Retrieve details of the currently authenticated user
+// @Tags users
+// @Accept json
+// @Produce json
+// @Success 200 {object} UserResponse
+// @Router /users/me [get]
+// @Security BearerAuth
+func GetCurrentUserHandler(repo UserRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userObjectId, ok := auth.CurrentUser(c)
+		if !ok {
+			return
+		}
+
+		user, err := repo.FindUserWithID(c.Request.Context(), userObjectId)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, UserResponse{
 			ID:       user.ID.Hex(),
 			Username: user.Username,
-			Token:    token,
+			Email:    user.Email,
+			Roles:    user.Roles,
+			Guest:    user.Guest,
+			Profile:  user.Profile.Response(),
+			Games: func() []string {
+				ids := make([]string, len(user.Games))
+				for i, id := range user.Games {
+					ids[i] = id.Hex()
+				}
+				return ids
+			}(),
 		})
 	}
 }
 
-// GetAllUsersHandler godoc
-// @Summary Get all users
-// @Description Retrieve a list of all users
+// ChangePasswordHandler godoc
+// @Summary Change password
+// @Description Change the current user's password. Every other session is logged out, API tokens are revoked and a new token pair is returned.
 // @Tags users
 // @Accept json
 // @Produce json
-// @Success 200 {array} User
-// @Router /users [get]
+// @Param password body ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} auth.TokenPair
+// @Router /users/me/password [post]
 // @Security BearerAuth
-func GetAllUsersHandler(repo UserRepository) gin.HandlerFunc {
+func ChangePasswordHandler(repo UserRepository, tokens *auth.Tokens) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userObjectId, ok := auth.CurrentUser(c)
+		if !ok {
+			return
+		}
+
+		var req ChangePasswordRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err := validPassword(req.NewPassword); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		user, err := repo.FindUserWithID(c.Request.Context(), userObjectId)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)) != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "current password is wrong"})
+			return
+		}
+
+		if err := repo.SetPassword(c.Request.Context(), user.ID, req.NewPassword); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if err := tokens.LogoutAll(c.Request.Context(), user.ID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if err := tokens.RevokeAPITokens(c.Request.Context(), user.ID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		pair, err := tokens.Issue(c.Request.Context(), user.ID, auth.ClientFrom(c))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
+			return
+		}
+		c.JSON(http.StatusOK, pair)
+	}
+}
+
+// ForgotPasswordHandler godoc
+// @Summary Request a password reset
+// @Description Email a single-use reset token to the account with this username or email. The response is the same whether or not the account exists.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param account body ForgotPasswordRequest true "Username or email"
+// @Success 200 {object} map[string]string
+// @Router /password/forgot [post]
+func ForgotPasswordHandler(repo UserRepository, resets ResetRepository, mailer mail.Mailer, resetURL string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		users, err := repo.GetAllUsers(c.Request.Context())
+		var req ForgotPasswordRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		var user User
+		var err error
+		switch {
+		case req.Email != "":
+			user, err = repo.FindUserWithEmail(c.Request.Context(), strings.ToLower(strings.TrimSpace(req.Email)))
+		case req.Username != "":
+			user, err = repo.FindUserWithUsername(c.Request.Context(), req.Username)
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "username or email is required"})
+			return
+		}
+
+		// Don't reveal which accounts exist
+		sent := gin.H{"message": "if the account has an email address, a reset link is on its way"}
+		if err != nil || user.Email == "" {
+			c.JSON(http.StatusOK, sent)
+			return
+		}
+
+		raw, hash, err := newResetToken()
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
-		c.JSON(http.StatusOK, users)
+		now := time.Now()
+		reset := PasswordReset{UserID: user.ID, Hash: hash, CreatedAt: now, ExpiresAt: now.Add(resetTokenTTL)}
+		if err := resets.Add(c.Request.Context(), reset); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		body := "Someone asked to reset the password for " + user.Username + ". Your reset code is:\n\n" + raw
+		if resetURL != "" {
+			body += "\n\nOr open " + resetURL + "?token=" + url.QueryEscape(raw)
+		}
+		body += "\n\nIt expires in an hour. If this wasn't you, ignore this email."
+		if err := mailer.Send(c.Request.Context(), mail.Message{To: user.Email, Subject: "Reset your password", Body: body}); err != nil {
+			log.Println("Failed to send reset email:", err)
+		}
+
+		c.JSON(http.StatusOK, sent)
 	}
 }
 
-// GetCurrentUserHandler godoc
-// @Summary Get current user
-// @Description Retrieve details of the currently authenticated user
+// ResetPasswordHandler godoc
+// @Summary Reset a forgotten password
+// @Description Set a new password with a reset token. The token works once, every session of the account is logged out and its API tokens are revoked.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param reset body ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} map[string]string
+// @Router /password/reset [post]
+func ResetPasswordHandler(repo UserRepository, resets ResetRepository, tokens *auth.Tokens) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req ResetPasswordRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err := validPassword(req.Password); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		reset, err := resets.Consume(c.Request.Context(), hashResetToken(req.Token), time.Now())
+		if err == ErrInvalidResetToken {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := repo.SetPassword(c.Request.Context(), reset.UserID, req.Password); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if err := tokens.LogoutAll(c.Request.Context(), reset.UserID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if err := tokens.RevokeAPITokens(c.Request.Context(), reset.UserID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "password reset, please log in again"})
+	}
+}
+
+// UpgradeGuestHandler godoc
+// @Summary Upgrade a guest account
+// @Description Turn the current guest into a full account with a username and password, keeping its games
 // @Tags users
 // @Accept json
 // @Produce json
+// @Param account body UpgradeRequest true "New account details"
 // @Success 200 {object} UserResponse
-// @Router /users/me [get]
+// @Router /users/me/upgrade [post]
 // @Security BearerAuth
-func GetCurrentUserHandler(repo UserRepository) gin.HandlerFunc {
+func UpgradeGuestHandler(repo UserRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		userID, exists := c.Get("user_id")
-		if !exists {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "user ID not found in context"})
+		userObjectId, ok := auth.CurrentUser(c)
+		if !ok {
 			return
 		}
 
-		userObjectId, err := primitive.ObjectIDFromHex(userID.(string))
+		var req UpgradeRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if ReservedUsername(req.Username) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": ErrReservedUsername.Error()})
+			return
+		}
+		if err := validPassword(req.Password); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		email, err := normalizeEmail(req.Email)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
-		user, err := repo.FindUserWithID(c.Request.Context(), userObjectId)
+		err = repo.UpgradeGuest(c.Request.Context(), userObjectId, req.Username, req.Password, email)
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "already a full account"})
+			return
+		}
+		if mongo.IsDuplicateKeyError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "username or email already exists"})
+			return
+		}
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
+		user, err := repo.FindUserWithID(c.Request.Context(), userObjectId)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusOK, UserResponse{
 			ID:       user.ID.Hex(),
 			Username: user.Username,
-			Games: func() []string {
-				ids := make([]string, len(user.Games))
-				for i, id := range user.Games {
-					ids[i] = id.Hex()
-				}
-				return ids
-			}(),
+			Email:    user.Email,
+			Roles:    user.Roles,
+			Profile:  user.Profile.Response(),
 		})
 	}
 }
+
+// UpdateProfileHandler godoc
+// @Summary Update profile
+// @Description Set the current user's display name, bio and pronouns
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param profile body UpdateProfileRequest true "Profile"
+// @Success 200 {object} ProfileResponse
+// @Router /users/me/profile [put]
+// @Security BearerAuth
+func UpdateProfileHandler(repo UserRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userObjectId, ok := auth.CurrentUser(c)
+		if !ok {
+			return
+		}
+
+		var req UpdateProfileRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		profile := Profile{
+			DisplayName: strings.TrimSpace(req.DisplayName),
+			Bio:         strings.TrimSpace(req.Bio),
+			Pronouns:    strings.TrimSpace(req.Pronouns),
+		}
+		if len([]rune(profile.DisplayName)) > 50 || len([]rune(profile.Bio)) > 300 || len([]rune(profile.Pronouns)) > 30 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "display name, bio or pronouns too long (50, 300 and 30 characters at most)"})
+			return
+		}
+
+		if err := repo.UpdateProfile(c.Request.Context(), userObjectId, profile); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		user, err := repo.FindUserWithID(c.Request.Context(), userObjectId)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, user.Profile.Response())
+	}
+}
+
+// UploadAvatarHandler godoc
+// @Summary Upload avatar
+// @Description Upload a JPEG, PNG or GIF of at most 5 MB as the current user's avatar. It is cropped square and resized.
+// @Tags users
+// @Accept multipart/form-data
+// @Produce json
+// @Param avatar formData file true "Avatar image"
+// @Success 200 {object} ProfileResponse
+// @Router /users/me/avatar [post]
+// @Security BearerAuth
+func UploadAvatarHandler(repo UserRepository, store AvatarStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userObjectId, ok := auth.CurrentUser(c)
+		if !ok {
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxAvatarBytes+1<<20)
+		file, err := c.FormFile("avatar")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "avatar file is required, at most 5 MB"})
+			return
+		}
+		if file.Size > maxAvatarBytes {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": ErrAvatarTooLarge.Error()})
+			return
+		}
+		f, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		data, err := io.ReadAll(io.LimitReader(f, maxAvatarBytes+1))
+		f.Close()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		images, err := ProcessAvatar(data)
+		if err == ErrAvatarTooLarge {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		user, err := repo.FindUserWithID(c.Request.Context(), userObjectId)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		key, err := NewAvatarKey(userObjectId.Hex())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		for size, img := range images {
+			if err := store.Save(c.Request.Context(), avatarFile(key, size), img); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+		}
+		if err := repo.SetAvatar(c.Request.Context(), userObjectId, key); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		DeleteAvatar(c.Request.Context(), store, user.Profile.Avatar)
+
+		user.Profile.Avatar = key
+		c.JSON(http.StatusOK, user.Profile.Response())
+	}
+}
+
+// DeleteAvatarHandler godoc
+// @Summary Delete avatar
+// @Description Remove the current user's avatar
+// @Tags users
+// @Produce json
+// @Success 200 {object} ProfileResponse
+// @Router /users/me/avatar [delete]
+// @Security BearerAuth
+func DeleteAvatarHandler(repo UserRepository, store AvatarStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userObjectId, ok := auth.CurrentUser(c)
+		if !ok {
+			return
+		}
+
+		user, err := repo.FindUserWithID(c.Request.Context(), userObjectId)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if err := repo.SetAvatar(c.Request.Context(), userObjectId, ""); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		DeleteAvatar(c.Request.Context(), store, user.Profile.Avatar)
+
+		user.Profile.Avatar = ""
+		c.JSON(http.StatusOK, user.Profile.Response())
+	}
+}
+
+// GetAvatarHandler godoc
+// @Summary Get an avatar image
+// @Description Serve an avatar image. URLs change whenever an avatar does, so they can be cached.
+// @Tags users
+// @Produce jpeg
+// @Param file path string true "Avatar file name"
+// @Success 200 {file} binary
+// @Router /avatars/{file} [get]
+func GetAvatarHandler(store AvatarStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		r, err := store.Open(c.Request.Context(), c.Param("file"))
+		if err == ErrAvatarNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		defer r.Close()
+
+		c.Header("Cache-Control", "public, max-age=31536000, immutable")
+		c.DataFromReader(http.StatusOK, -1, "image/jpeg", r, nil)
+	}
+}
+
+// DeleteAvatar removes every size of a stored avatar.
+func DeleteAvatar(ctx context.Context, store AvatarStore, key string) {
+	if key == "" {
+		return
+	}
+	for _, size := range []int{AvatarSize, AvatarThumbSize} {
+		if err := store.Delete(ctx, avatarFile(key, size)); err != nil {
+			log.Println("Failed to delete old avatar:", err)
+		}
+	}
+}
+
+func normalizeEmail(email string) (string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return "", nil
+	}
+	addr, err := netmail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return "", errors.New("invalid email address")
+	}
+	return email, nil
+}
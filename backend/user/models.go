@@ -1,16 +1,64 @@
 package user
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
 
 type User struct {
-	ID       primitive.ObjectID   `bson:"_id,omitempty"`
-	Username string               `bson:"username"`
-	Password string               `bson:"password"`
-	Games    []primitive.ObjectID `bson:"games,omitempty"`
+	ID         primitive.ObjectID   `bson:"_id,omitempty"`
+	Username   string               `bson:"username"`
+	Password   string               `bson:"password"`
+	Email      string               `bson:"email,omitempty"` // for password resets
+	Games      []primitive.ObjectID `bson:"games,omitempty"`
+	Identities []Identity           `bson:"identities,omitempty"` // external logins
+	Profile    Profile              `bson:"profile,omitempty"`
+	Roles      []string             `bson:"roles,omitempty"`
+	Disabled   bool                 `bson:"disabled,omitempty"` // blocked by an administrator
+	Guest      bool                 `bson:"guest,omitempty"`    // joined through an invite without signing up
+	LastSeen   time.Time            `bson:"lastSeen,omitempty"` // guests only, for cleanup
+}
+
+// Profile is what other players see about a user.
+type Profile struct {
+	DisplayName string `bson:"displayName,omitempty"`
+	Bio         string `bson:"bio,omitempty"`
+	Pronouns    string `bson:"pronouns,omitempty"`
+	Avatar      string `bson:"avatar,omitempty"` // key of the stored avatar images
+}
+
+type ProfileResponse struct {
+	DisplayName    string `json:"displayName,omitempty"`
+	Bio            string `json:"bio,omitempty"`
+	Pronouns       string `json:"pronouns,omitempty"`
+	AvatarURL      string `json:"avatarUrl,omitempty"`
+	AvatarThumbURL string `json:"avatarThumbUrl,omitempty"`
+}
+
+func (p Profile) Response() ProfileResponse {
+	r := ProfileResponse{DisplayName: p.DisplayName, Bio: p.Bio, Pronouns: p.Pronouns}
+	if p.Avatar != "" {
+		r.AvatarURL = AvatarURL(p.Avatar, AvatarSize)
+		r.AvatarThumbURL = AvatarURL(p.Avatar, AvatarThumbSize)
+	}
+	return r
+}
+
+// Identity links an account to a user at an OpenID provider.
+type Identity struct {
+	Provider string    `bson:"provider" json:"provider"`
+	Subject  string    `bson:"subject" json:"-"`
+	Email    string    `bson:"email,omitempty" json:"email,omitempty"`
+	LinkedAt time.Time `bson:"linkedAt" json:"linkedAt"`
 }
 
 type UserResponse struct {
-	ID       string   `json:"id"`
-	Username string   `json:"username"`
-	Games    []string `json:"games,omitempty"`
+	ID       string          `json:"id"`
+	Username string          `json:"username"`
+	Email    string          `json:"email,omitempty"` // only shown to the user themselves
+	Games    []string        `json:"games,omitempty"`
+	Roles    []string        `json:"roles,omitempty"`
+	Guest    bool            `json:"guest,omitempty"`
+	Profile  ProfileResponse `json:"profile"`
 }
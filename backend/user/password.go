@@ -0,0 +1,92 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	resetTokenTTL     = time.Hour
+	minPasswordLength = 8
+)
+
+var ErrInvalidResetToken = errors.New("invalid or expired reset token")
+
+// PasswordReset is a single-use reset token, stored by hash only.
+type PasswordReset struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	UserID    primitive.ObjectID `bson:"userId"`
+	Hash      string             `bson:"hash"`
+	Used      bool               `bson:"used"`
+	CreatedAt time.Time          `bson:"createdAt"`
+	ExpiresAt time.Time          `bson:"expiresAt"`
+}
+
+type ResetRepository interface {
+	Add(ctx context.Context, r PasswordReset) error
+	// Consume marks an unused, unexpired token as used and returns it.
+	Consume(ctx context.Context, hash string, now time.Time) (PasswordReset, error)
+	RemoveUser(ctx context.Context, userID primitive.ObjectID) error
+}
+
+type mongoResetRepository struct {
+	col *mongo.Collection
+}
+
+func NewMongoResetRepository(col *mongo.Collection) ResetRepository {
+	return &mongoResetRepository{col: col}
+}
+
+func (r *mongoResetRepository) Add(ctx context.Context, reset PasswordReset) error {
+	_, err := r.col.InsertOne(ctx, reset)
+	return err
+}
+
+func (r *mongoResetRepository) Consume(ctx context.Context, hash string, now time.Time) (PasswordReset, error) {
+	var reset PasswordReset
+	err := r.col.FindOneAndUpdate(
+		ctx,
+		bson.M{"hash": hash, "used": false, "expiresAt": bson.M{"$gt": now}},
+		bson.M{"$set": bson.M{"used": true}},
+	).Decode(&reset)
+	if err == mongo.ErrNoDocuments {
+		return reset, ErrInvalidResetToken
+	}
+	return reset, err
+}
+
+func (r *mongoResetRepository) RemoveUser(ctx context.Context, userID primitive.ObjectID) error {
+	_, err := r.col.DeleteMany(ctx, bson.M{"userId": userID})
+	return err
+}
+
+// newResetToken returns a random token and the hash to store for it.
+func newResetToken() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	raw := base64.RawURLEncoding.EncodeToString(buf)
+	return raw, hashResetToken(raw), nil
+}
+
+func hashResetToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+func validPassword(password string) error {
+	if len(password) < minPasswordLength {
+		return errors.New("password must be at least 8 characters")
+	}
+	return nil
+}
@@ -2,10 +2,13 @@ package user
 
 import (
 	"context"
+	"regexp"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -14,7 +17,21 @@ type UserRepository interface {
 	AddUser(context context.Context, user User) error
 	FindUserWithID(context context.Context, id primitive.ObjectID) (User, error)
 	FindUserWithUsername(context context.Context, username string) (User, error)
-	GetAllUsers(context context.Context) ([]UserResponse, error)
+	FindUserWithEmail(context context.Context, email string) (User, error)
+	SetPassword(context context.Context, userID primitive.ObjectID, password string) error
+	FindUserWithIdentity(context context.Context, provider, subject string) (User, error)
+	AddIdentity(context context.Context, userID primitive.ObjectID, identity Identity) error
+	RemoveIdentity(context context.Context, userID primitive.ObjectID, provider string) error
+	UpdateProfile(context context.Context, userID primitive.ObjectID, profile Profile) error
+	SetAvatar(context context.Context, userID primitive.ObjectID, avatar string) error
+	DeleteUser(context context.Context, userID primitive.ObjectID) error
+	SearchUsers(context context.Context, query string, skip, limit int64) ([]User, int64, error)
+	SetRoles(context context.Context, userID primitive.ObjectID, roles []string) error
+	SetDisabled(context context.Context, userID primitive.ObjectID, disabled bool) error
+	Stats(context context.Context) (UserStats, error)
+	UpgradeGuest(context context.Context, userID primitive.ObjectID, username, password, email string) error
+	FindGuests(context context.Context, seenBefore time.Time) ([]User, error)
+	TouchGuest(context context.Context, userID primitive.ObjectID, seen time.Time) error
 	AddGameToUser(context context.Context, userID primitive.ObjectID, gameID primitive.ObjectID) error
 }
 
@@ -38,11 +55,6 @@ func (r *mongoRepository) AddUser(context context.Context, user User) error {
 }
 
 func (r *mongoRepository) AddGameToUser(context context.Context, userID primitive.ObjectID, gameID primitive.ObjectID) error {
-	// Convert string ID to ObjectID
-	r.collection.UpdateByID(context, userID, bson.M{
-		"$addToSet": bson.M{"friends": "asdflkj"},
-	})
-
 	result, err := r.collection.UpdateByID(context, userID, bson.M{
 		"$addToSet": bson.M{"games": gameID},
 	})
@@ -70,23 +82,231 @@ func (r *mongoRepository) FindUserWithUsername(context context.Context, username
 	return user, err
 }
 
-func (r *mongoRepository) GetAllUsers(context context.Context) ([]UserResponse, error) {
-	var dbUsers []User
-	cursor, err := r.collection.Find(context, bson.M{})
+func (r *mongoRepository) FindUserWithEmail(context context.Context, email string) (User, error) {
+	var user User
+	err := r.collection.FindOne(context, bson.M{"email": email}).Decode(&user)
+	return user, err
+}
+
+// SetPassword hashes and stores a new password.
+func (r *mongoRepository) SetPassword(context context.Context, userID primitive.ObjectID, password string) error {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	if err = cursor.All(context, &dbUsers); err != nil {
-		return nil, err
+
+	result, err := r.collection.UpdateByID(context, userID, bson.M{"$set": bson.M{"password": string(hashed)}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *mongoRepository) FindUserWithIdentity(context context.Context, provider, subject string) (User, error) {
+	var user User
+	err := r.collection.FindOne(context, bson.M{
+		"identities": bson.M{"$elemMatch": bson.M{"provider": provider, "subject": subject}},
+	}).Decode(&user)
+	return user, err
+}
+
+// AddIdentity links an external login, replacing any earlier link to the
+// same provider.
+func (r *mongoRepository) AddIdentity(context context.Context, userID primitive.ObjectID, identity Identity) error {
+	_, err := r.collection.UpdateByID(context, userID, bson.M{
+		"$pull": bson.M{"identities": bson.M{"provider": identity.Provider}},
+	})
+	if err != nil {
+		return err
+	}
+
+	result, err := r.collection.UpdateByID(context, userID, bson.M{
+		"$push": bson.M{"identities": identity},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *mongoRepository) RemoveIdentity(context context.Context, userID primitive.ObjectID, provider string) error {
+	result, err := r.collection.UpdateByID(context, userID, bson.M{
+		"$pull": bson.M{"identities": bson.M{"provider": provider}},
+	})
+	if err != nil {
+		return err
+	}
+	if result.ModifiedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// UpdateProfile sets the text fields of a profile, leaving the avatar.
+func (r *mongoRepository) UpdateProfile(context context.Context, userID primitive.ObjectID, profile Profile) error {
+	result, err := r.collection.UpdateByID(context, userID, bson.M{"$set": bson.M{
+		"profile.displayName": profile.DisplayName,
+		"profile.bio":         profile.Bio,
+		"profile.pronouns":    profile.Pronouns,
+	}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// SetAvatar points a profile at new avatar images, or removes it when
+// avatar is empty.
+func (r *mongoRepository) SetAvatar(context context.Context, userID primitive.ObjectID, avatar string) error {
+	update := bson.M{"$set": bson.M{"profile.avatar": avatar}}
+	if avatar == "" {
+		update = bson.M{"$unset": bson.M{"profile.avatar": ""}}
+	}
+	result, err := r.collection.UpdateByID(context, userID, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
 	}
+	return nil
+}
+
+func (r *mongoRepository) DeleteUser(context context.Context, userID primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(context, bson.M{"_id": userID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// SearchUsers pages through users whose username, display name or email
+// contains query, returning the page and the total number of matches.
+func (r *mongoRepository) SearchUsers(context context.Context, query string, skip, limit int64) ([]User, int64, error) {
+	filter := bson.M{}
+	if query != "" {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(query), Options: "i"}
+		filter = bson.M{"$or": bson.A{
+			bson.M{"username": pattern},
+			bson.M{"profile.displayName": pattern},
+			bson.M{"email": pattern},
+		}}
+	}
+	total, err := r.collection.CountDocuments(context, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().SetSort(bson.M{"username": 1}).SetSkip(skip).SetLimit(limit)
+	cursor, err := r.collection.Find(context, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	users := []User{}
+	if err := cursor.All(context, &users); err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+func (r *mongoRepository) SetRoles(context context.Context, userID primitive.ObjectID, roles []string) error {
+	update := bson.M{"$set": bson.M{"roles": roles}}
+	if len(roles) == 0 {
+		update = bson.M{"$unset": bson.M{"roles": ""}}
+	}
+	result, err := r.collection.UpdateByID(context, userID, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *mongoRepository) SetDisabled(context context.Context, userID primitive.ObjectID, disabled bool) error {
+	update := bson.M{"$set": bson.M{"disabled": true}}
+	if !disabled {
+		update = bson.M{"$unset": bson.M{"disabled": ""}}
+	}
+	result, err := r.collection.UpdateByID(context, userID, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *mongoRepository) Stats(context context.Context) (UserStats, error) {
+	var stats UserStats
+	var err error
+	if stats.Total, err = r.collection.CountDocuments(context, bson.M{}); err != nil {
+		return stats, err
+	}
+	if stats.Disabled, err = r.collection.CountDocuments(context, bson.M{"disabled": true}); err != nil {
+		return stats, err
+	}
+	if stats.Admins, err = r.collection.CountDocuments(context, bson.M{"roles": RoleAdmin}); err != nil {
+		return stats, err
+	}
+	if stats.Guests, err = r.collection.CountDocuments(context, bson.M{"guest": true}); err != nil {
+		return stats, err
+	}
+	return stats, nil
+}
+
+// UpgradeGuest turns a guest into a full account, keeping its ID and so
+// its games. It fails with mongo.ErrNoDocuments if the user isn't a guest.
+func (r *mongoRepository) UpgradeGuest(context context.Context, userID primitive.ObjectID, username, password, email string) error {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	set := bson.M{"username": username, "password": string(hashed)}
+	unset := bson.M{"guest": "", "lastSeen": ""}
+	if email != "" {
+		set["email"] = email
+	} else {
+		unset["email"] = ""
+	}
+	result, err := r.collection.UpdateOne(context, bson.M{"_id": userID, "guest": true}, bson.M{"$set": set, "$unset": unset})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
 
-	var responseUsers []UserResponse = make([]UserResponse, len(dbUsers))
-	for i, user := range dbUsers {
-		responseUsers[i] = UserResponse{
-			ID:       user.ID.Hex(),
-			Username: user.Username,
-		}
+// FindGuests returns guests last seen before the given time.
+func (r *mongoRepository) FindGuests(context context.Context, seenBefore time.Time) ([]User, error) {
+	cursor, err := r.collection.Find(context, bson.M{"guest": true, "lastSeen": bson.M{"$lt": seenBefore}})
+	if err != nil {
+		return nil, err
 	}
+	var users []User
+	if err := cursor.All(context, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
 
-	return responseUsers, nil
+func (r *mongoRepository) TouchGuest(context context.Context, userID primitive.ObjectID, seen time.Time) error {
+	_, err := r.collection.UpdateOne(context, bson.M{"_id": userID, "guest": true}, bson.M{"$set": bson.M{"lastSeen": seen}})
+	return err
 }
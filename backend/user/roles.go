@@ -0,0 +1,63 @@
+package user
+
+import (
+	"context"
+	"slices"
+
+	"irl-mafia-game/auth"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	RoleAdmin  = "admin"  // manages accounts and games through /admin
+	RoleMember = "member" // held by every full account but never stored; guests lack it
+)
+
+// KnownRoles are the roles that can be granted.
+var KnownRoles = []string{RoleAdmin}
+
+type UserStats struct {
+	Total    int64 `json:"total"`
+	Disabled int64 `json:"disabled"`
+	Admins   int64 `json:"admins"`
+	Guests   int64 `json:"guests"`
+}
+
+func (u User) HasRole(role string) bool {
+	return slices.Contains(u.Roles, role)
+}
+
+// RoleLookup looks roles up on every request, so granting, revoking and
+// disabling take effect without waiting for tokens to expire. Disabled
+// users have no roles.
+func RoleLookup(repo UserRepository) auth.RoleLookup {
+	return func(ctx context.Context, userID string) ([]string, error) {
+		id, err := primitive.ObjectIDFromHex(userID)
+		if err != nil {
+			return nil, err
+		}
+		u, err := repo.FindUserWithID(ctx, id)
+		if err != nil || u.Disabled {
+			return nil, err
+		}
+		roles := u.Roles
+		if !u.Guest {
+			roles = append(slices.Clip(roles), RoleMember)
+		}
+		return roles, nil
+	}
+}
+
+// GrantRole adds a role to the named user, used to bootstrap the first
+// administrators.
+func GrantRole(ctx context.Context, repo UserRepository, username, role string) error {
+	u, err := repo.FindUserWithUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+	if u.HasRole(role) {
+		return nil
+	}
+	return repo.SetRoles(ctx, u.ID, append(u.Roles, role))
+}
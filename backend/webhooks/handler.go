@@ -0,0 +1,232 @@
+package webhooks
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"irl-mafia-game/game"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" binding:"required"`
+	Events []string `json:"events"` // payload types to send, empty for all
+}
+
+// CreateWebhookHandler godoc
+// @Summary Register a webhook
+// @Description Let the host register a URL that receives signed JSON for the game's public events. The secret is only returned here.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "Game ID"
+// @Param webhook body CreateWebhookRequest true "Webhook info"
+// @Success 200 {object} Webhook
+// @Router /games/{id}/webhooks [post]
+// @Security BearerAuth
+func CreateWebhookHandler(repo WebhookRepository, gameRepo game.GameRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req CreateWebhookRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		u, err := url.Parse(req.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "webhook URL must be an absolute http(s) URL"})
+			return
+		}
+		if ip, err := netip.ParseAddr(u.Hostname()); err == nil && !PublicAddr(ip) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": ErrPrivateAddress.Error()})
+			return
+		}
+
+		host, g, ok := hostGame(c, gameRepo)
+		if !ok {
+			return
+		}
+
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate secret"})
+			return
+		}
+
+		hook := Webhook{
+			GameID:    g.ID,
+			URL:       req.URL,
+			Secret:    hex.EncodeToString(secret),
+			Events:    req.Events,
+			CreatedBy: host,
+			CreatedAt: time.Now(),
+		}
+		id, err := repo.Create(c.Request.Context(), hook)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		hook.ID = id
+
+		c.JSON(http.StatusOK, hook)
+	}
+}
+
+// GetWebhooksHandler godoc
+// @Summary List webhooks
+// @Description Let the host list a game's webhooks
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Game ID"
+// @Success 200 {array} Webhook
+// @Router /games/{id}/webhooks [get]
+// @Security BearerAuth
+func GetWebhooksHandler(repo WebhookRepository, gameRepo game.GameRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_, g, ok := hostGame(c, gameRepo)
+		if !ok {
+			return
+		}
+
+		hooks, err := repo.FindByGame(c.Request.Context(), g.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		for i := range hooks {
+			hooks[i].Secret = ""
+		}
+		if hooks == nil {
+			hooks = []Webhook{}
+		}
+		c.JSON(http.StatusOK, hooks)
+	}
+}
+
+// DeleteWebhookHandler godoc
+// @Summary Delete a webhook
+// @Description Let the host remove a webhook and its delivery log
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Game ID"
+// @Param webhookId path string true "Webhook ID"
+// @Success 200 {object} map[string]string
+// @Router /games/{id}/webhooks/{webhookId} [delete]
+// @Security BearerAuth
+func DeleteWebhookHandler(repo WebhookRepository, gameRepo game.GameRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		hook, ok := hostWebhook(c, repo, gameRepo)
+		if !ok {
+			return
+		}
+
+		if err := repo.Delete(c.Request.Context(), hook.ID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "deleted"})
+	}
+}
+
+// PingWebhookHandler godoc
+// @Summary Ping a webhook
+// @Description Send a signed test payload right away and return the delivery
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Game ID"
+// @Param webhookId path string true "Webhook ID"
+// @Success 200 {object} Delivery
+// @Router /games/{id}/webhooks/{webhookId}/ping [post]
+// @Security BearerAuth
+func PingWebhookHandler(repo WebhookRepository, gameRepo game.GameRepository, sender *Sender) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		hook, ok := hostWebhook(c, repo, gameRepo)
+		if !ok {
+			return
+		}
+
+		// One attempt only, the caller is waiting
+		ping := *sender
+		ping.MaxAttempts = 1
+		delivery, err := ping.Deliver(c.Request.Context(), hook, Payload{Type: PayloadPing})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, delivery)
+	}
+}
+
+// GetDeliveriesHandler godoc
+// @Summary List webhook deliveries
+// @Description Show the most recent deliveries to a webhook with every attempt
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Game ID"
+// @Param webhookId path string true "Webhook ID"
+// @Success 200 {array} Delivery
+// @Router /games/{id}/webhooks/{webhookId}/deliveries [get]
+// @Security BearerAuth
+func GetDeliveriesHandler(repo WebhookRepository, gameRepo game.GameRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		hook, ok := hostWebhook(c, repo, gameRepo)
+		if !ok {
+			return
+		}
+
+		deliveries, err := repo.FindDeliveries(c.Request.Context(), hook.ID, 50)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if deliveries == nil {
+			deliveries = []Delivery{}
+		}
+		c.JSON(http.StatusOK, deliveries)
+	}
+}
+
+// hostGame loads the game in the path and checks the current user hosts
+// it. On failure it writes the error response and returns false.
+func hostGame(c *gin.Context, gameRepo game.GameRepository) (primitive.ObjectID, game.Game, bool) {
+	userObjID, g, ok := game.LoadGame(c, gameRepo)
+	if !ok {
+		return primitive.NilObjectID, game.Game{}, false
+	}
+	if g.Host != userObjID {
+		c.JSON(http.StatusForbidden, gin.H{"error": game.ErrNotHost.Error()})
+		return primitive.NilObjectID, game.Game{}, false
+	}
+	return userObjID, g, true
+}
+
+// hostWebhook loads the webhook in the path, checking it belongs to a game
+// the current user hosts.
+func hostWebhook(c *gin.Context, repo WebhookRepository, gameRepo game.GameRepository) (Webhook, bool) {
+	_, g, ok := hostGame(c, gameRepo)
+	if !ok {
+		return Webhook{}, false
+	}
+
+	hookID, err := primitive.ObjectIDFromHex(c.Param("webhookId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook ID"})
+		return Webhook{}, false
+	}
+	hook, err := repo.FindByID(c.Request.Context(), hookID)
+	if err == mongo.ErrNoDocuments || (err == nil && hook.GameID != g.ID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "webhook not found"})
+		return Webhook{}, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return Webhook{}, false
+	}
+	return hook, true
+}
@@ -0,0 +1,67 @@
+package webhooks
+
+import (
+	"irl-mafia-game/game"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// Payload types besides game event types.
+const (
+	PayloadPing         = "ping"
+	PayloadDailySummary = "daily_summary"
+)
+
+// Webhook is a URL a host registered to receive a game's public events.
+type Webhook struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	GameID    primitive.ObjectID `bson:"gameId" json:"gameId"`
+	URL       string             `bson:"url" json:"url"`
+	Secret    string             `bson:"secret" json:"secret,omitempty"` // HMAC key, only shown on creation
+	Events    []string           `bson:"events" json:"events"`           // payload types to send, empty for all
+	CreatedBy primitive.ObjectID `bson:"createdBy" json:"createdBy"`
+	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
+}
+
+// Delivery logs one payload sent to a webhook and every attempt at it.
+type Delivery struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	WebhookID   primitive.ObjectID `bson:"webhookId" json:"webhookId"`
+	GameID      primitive.ObjectID `bson:"gameId" json:"gameId"`
+	Type        string             `bson:"type" json:"type"`
+	Payload     string             `bson:"payload" json:"payload"`
+	Status      string             `bson:"status" json:"status"`
+	Attempts    []Attempt          `bson:"attempts" json:"attempts"`
+	CreatedAt   time.Time          `bson:"createdAt" json:"createdAt"`
+	DeliveredAt time.Time          `bson:"deliveredAt,omitempty" json:"deliveredAt,omitempty"`
+}
+
+type Attempt struct {
+	Time       time.Time `bson:"time" json:"time"`
+	StatusCode int       `bson:"statusCode,omitempty" json:"statusCode,omitempty"`
+	Error      string    `bson:"error,omitempty" json:"error,omitempty"`
+}
+
+// Payload is the JSON body posted to webhooks.
+type Payload struct {
+	ID      string      `json:"id"` // delivery ID, stable across retries
+	Type    string      `json:"type"`
+	GameID  string      `json:"gameId"`
+	SentAt  time.Time   `json:"sentAt"`
+	Event   *game.Event `json:"event,omitempty"`
+	Summary *Summary    `json:"summary,omitempty"`
+}
+
+// Summary recaps one day of a game.
+type Summary struct {
+	Day       int             `json:"day"`
+	Events    []game.Event    `json:"events"`
+	Standings []game.Standing `json:"standings"`
+}
@@ -0,0 +1,94 @@
+package webhooks
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type WebhookRepository interface {
+	Create(ctx context.Context, w Webhook) (primitive.ObjectID, error)
+	FindByID(ctx context.Context, id primitive.ObjectID) (Webhook, error)
+	FindByGame(ctx context.Context, gameID primitive.ObjectID) ([]Webhook, error)
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	AddDelivery(ctx context.Context, d Delivery) (primitive.ObjectID, error)
+	UpdateDelivery(ctx context.Context, d Delivery) error
+	FindDeliveries(ctx context.Context, webhookID primitive.ObjectID, limit int64) ([]Delivery, error)
+}
+
+type mongoRepository struct {
+	hooks      *mongo.Collection
+	deliveries *mongo.Collection
+}
+
+func NewMongoRepository(hooks, deliveries *mongo.Collection) WebhookRepository {
+	return &mongoRepository{hooks: hooks, deliveries: deliveries}
+}
+
+func (r *mongoRepository) Create(ctx context.Context, w Webhook) (primitive.ObjectID, error) {
+	res, err := r.hooks.InsertOne(ctx, w)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	return res.InsertedID.(primitive.ObjectID), nil
+}
+
+func (r *mongoRepository) FindByID(ctx context.Context, id primitive.ObjectID) (Webhook, error) {
+	var w Webhook
+	err := r.hooks.FindOne(ctx, bson.M{"_id": id}).Decode(&w)
+	return w, err
+}
+
+func (r *mongoRepository) FindByGame(ctx context.Context, gameID primitive.ObjectID) ([]Webhook, error) {
+	var hooks []Webhook
+	cursor, err := r.hooks.Find(ctx, bson.M{"gameId": gameID})
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(ctx, &hooks); err != nil {
+		return nil, err
+	}
+	return hooks, nil
+}
+
+func (r *mongoRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := r.hooks.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	_, err = r.deliveries.DeleteMany(ctx, bson.M{"webhookId": id})
+	return err
+}
+
+func (r *mongoRepository) AddDelivery(ctx context.Context, d Delivery) (primitive.ObjectID, error) {
+	res, err := r.deliveries.InsertOne(ctx, d)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	return res.InsertedID.(primitive.ObjectID), nil
+}
+
+func (r *mongoRepository) UpdateDelivery(ctx context.Context, d Delivery) error {
+	_, err := r.deliveries.ReplaceOne(ctx, bson.M{"_id": d.ID}, d)
+	return err
+}
+
+// FindDeliveries returns a webhook's most recent deliveries first.
+func (r *mongoRepository) FindDeliveries(ctx context.Context, webhookID primitive.ObjectID, limit int64) ([]Delivery, error) {
+	var deliveries []Delivery
+	opts := options.Find().SetSort(bson.M{"createdAt": -1}).SetLimit(limit)
+	cursor, err := r.deliveries.Find(ctx, bson.M{"webhookId": webhookID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(ctx, &deliveries); err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
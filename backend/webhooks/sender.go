@@ -0,0 +1,229 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"irl-mafia-game/game"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"syscall"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	SignatureHeader = "X-Mafia-Signature"
+	TimestampHeader = "X-Mafia-Timestamp"
+	DeliveryHeader  = "X-Mafia-Delivery"
+)
+
+// Sender signs and posts payloads to webhooks, retrying failures with
+// exponential backoff and logging every attempt.
+type Sender struct {
+	repo        WebhookRepository
+	client      *http.Client
+	MaxAttempts int
+	Backoff     time.Duration // wait before the first retry, doubled after each
+}
+
+func NewSender(repo WebhookRepository) *Sender {
+	dialer := &net.Dialer{Timeout: 5 * time.Second, KeepAlive: 30 * time.Second, Control: publicOnly}
+	transport := &http.Transport{
+		DialContext:         dialer.DialContext,
+		ForceAttemptHTTP2:   true,
+		MaxIdleConns:        20,
+		IdleConnTimeout:     90 * time.Second,
+		TLSHandshakeTimeout: 5 * time.Second,
+	}
+	return &Sender{
+		repo:        repo,
+		client:      &http.Client{Transport: transport, Timeout: 10 * time.Second},
+		MaxAttempts: 5,
+		Backoff:     2 * time.Second,
+	}
+}
+
+// ErrPrivateAddress is why deliveries to internal addresses fail.
+var ErrPrivateAddress = errors.New("webhook address is not public")
+
+// publicOnly is a dialer Control hook refusing connections to private,
+// loopback and link-local addresses. It runs after DNS resolution, so it
+// also catches hostnames and redirects that lead to internal services.
+func publicOnly(network, address string, _ syscall.RawConn) error {
+	addr, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !PublicAddr(addr.Addr()) {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, addr.Addr())
+	}
+	return nil
+}
+
+// PublicAddr reports whether webhooks may be sent to ip.
+func PublicAddr(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsGlobalUnicast() && !ip.IsPrivate()
+}
+
+// Sign computes the signature receivers check: the hex HMAC-SHA256 of the
+// timestamp, a dot and the body.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Deliver sends one payload to a webhook, retrying until it is accepted or
+// MaxAttempts run out. The returned delivery holds every attempt.
+func (s *Sender) Deliver(ctx context.Context, hook Webhook, p Payload) (Delivery, error) {
+	d := Delivery{
+		WebhookID: hook.ID,
+		GameID:    hook.GameID,
+		Type:      p.Type,
+		Status:    DeliveryPending,
+		CreatedAt: time.Now(),
+	}
+	id, err := s.repo.AddDelivery(ctx, d)
+	if err != nil {
+		return d, err
+	}
+	d.ID = id
+
+	p.ID = id.Hex()
+	p.GameID = hook.GameID.Hex()
+	p.SentAt = d.CreatedAt
+	body, err := json.Marshal(p)
+	if err != nil {
+		return d, err
+	}
+	d.Payload = string(body)
+
+	wait := s.Backoff
+	for attempt := 1; attempt <= s.MaxAttempts; attempt++ {
+		a := s.post(ctx, hook, d.ID.Hex(), body)
+		d.Attempts = append(d.Attempts, a)
+		if a.Error == "" {
+			d.Status = DeliveryDelivered
+			d.DeliveredAt = a.Time
+			break
+		}
+		if attempt == s.MaxAttempts {
+			d.Status = DeliveryFailed
+			break
+		}
+		if err := s.repo.UpdateDelivery(ctx, d); err != nil {
+			log.Println("Failed to log webhook attempt:", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			d.Status = DeliveryFailed
+			return d, s.repo.UpdateDelivery(context.Background(), d)
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+	return d, s.repo.UpdateDelivery(ctx, d)
+}
+
+func (s *Sender) post(ctx context.Context, hook Webhook, deliveryID string, body []byte) Attempt {
+	now := time.Now()
+	a := Attempt{Time: now}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		a.Error = err.Error()
+		return a
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(TimestampHeader, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(SignatureHeader, Sign(hook.Secret, now.Unix(), body))
+	req.Header.Set(DeliveryHeader, deliveryID)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		a.Error = err.Error()
+		return a
+	}
+	resp.Body.Close()
+
+	a.StatusCode = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		a.Error = fmt.Sprintf("unexpected status %d", resp.StatusCode)
+	}
+	return a
+}
+
+// GameListener posts every new public event to the game's webhooks, plus
+// a summary of the previous day when the first event of a new day comes
+// in.
+func (s *Sender) GameListener() game.Listener {
+	return func(g game.Game, events []game.Event) {
+		var payloads []Payload
+		for _, ev := range events {
+			if public, ok := ev.RedactedFor(primitive.NilObjectID); ok {
+				payloads = append(payloads, Payload{Type: ev.Type, Event: &public})
+			}
+		}
+
+		if first := len(g.Events) - len(events); first > 0 && len(events) > 0 {
+			prevDay := g.Day(g.Events[first-1].Time)
+			if g.Day(events[0].Time) > prevDay {
+				payloads = append(payloads, Payload{Type: PayloadDailySummary, Summary: DailySummary(g, prevDay)})
+			}
+		}
+		if len(payloads) == 0 {
+			return
+		}
+
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			defer cancel()
+
+			hooks, err := s.repo.FindByGame(ctx, g.ID)
+			if err != nil {
+				log.Println("Failed to load webhooks:", err)
+				return
+			}
+			for _, hook := range hooks {
+				for _, p := range payloads {
+					if !hook.wants(p.Type) {
+						continue
+					}
+					if _, err := s.Deliver(ctx, hook, p); err != nil {
+						log.Println("Failed to deliver webhook:", err)
+					}
+				}
+			}
+		}()
+	}
+}
+
+// DailySummary recaps a day's public events and the current standings.
+func DailySummary(g game.Game, day int) *Summary {
+	return &Summary{Day: day, Events: g.PublicEvents(day), Standings: g.Standings()}
+}
+
+func (w Webhook) wants(payloadType string) bool {
+	if len(w.Events) == 0 || payloadType == PayloadPing {
+		return true
+	}
+	for _, t := range w.Events {
+		if t == payloadType {
+			return true
+		}
+	}
+	return false
+}
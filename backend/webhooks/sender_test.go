@@ -0,0 +1,54 @@
+package webhooks
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+func TestPublicAddr(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"fd00::1", false},
+		{"169.254.169.254", false}, // cloud metadata
+		{"fe80::1", false},
+		{"0.0.0.0", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:10.0.0.1", false},
+	}
+	for _, tt := range tests {
+		if got := PublicAddr(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("PublicAddr(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestSenderRefusesInternalHosts(t *testing.T) {
+	hit := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hit = true
+	}))
+	defer srv.Close()
+
+	// localhost only turns out to be internal once it is resolved
+	url := strings.Replace(srv.URL, "127.0.0.1", "localhost", 1)
+	a := NewSender(nil).post(context.Background(), Webhook{URL: url, Secret: "s"}, "d", []byte("{}"))
+	if !strings.Contains(a.Error, ErrPrivateAddress.Error()) {
+		t.Errorf("attempt error = %q, want %q", a.Error, ErrPrivateAddress)
+	}
+	if hit {
+		t.Error("the internal server was reached")
+	}
+}
@@ -1,11 +1,16 @@
 import axios from "axios";
 import AsyncStorage from "@react-native-async-storage/async-storage";
+import { Platform } from "react-native";
+
+// Shown in the list of sessions a user is logged in on
+const deviceName = `IRL Mafia (${Platform.OS})`;
 
 // Create instance
 const api = axios.create({
   baseURL: "http://localhost:8080/", // Replace with your API base URL
   headers: {
     "Content-Type": "application/json",
+    "X-Device-Name": deviceName,
   },
 });
 
@@ -21,4 +26,44 @@ api.interceptors.request.use(
   (error) => Promise.reject(error)
 );
 
+// Access tokens are short-lived: on a 401, swap the refresh token for a
+// new pair once and retry the request
+let refreshing: Promise<string | null> | null = null;
+
+const refreshToken = async (): Promise<string | null> => {
+  const refresh = await AsyncStorage.getItem("refreshToken");
+  if (!refresh) return null;
+  try {
+    const { data } = await axios.post(
+      `${api.defaults.baseURL}token/refresh`,
+      { refreshToken: refresh },
+      { headers: { "X-Device-Name": deviceName } }
+    );
+    await AsyncStorage.setItem("jwt", data.token);
+    await AsyncStorage.setItem("refreshToken", data.refreshToken);
+    return data.token;
+  } catch {
+    await AsyncStorage.multiRemove(["jwt", "refreshToken"]);
+    return null;
+  }
+};
+
+api.interceptors.response.use(
+  (response) => response,
+  async (error) => {
+    const config = error.config;
+    if (error.response?.status !== 401 || !config || config._retried) {
+      return Promise.reject(error);
+    }
+    config._retried = true;
+
+    refreshing = refreshing ?? refreshToken().finally(() => (refreshing = null));
+    const token = await refreshing;
+    if (!token) return Promise.reject(error);
+
+    config.headers.Authorization = `Bearer ${token}`;
+    return api(config);
+  }
+);
+
 export default api;
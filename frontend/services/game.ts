@@ -6,5 +6,5 @@ export const fetchUsernameFromGameId = async (
   console.log("Fetching users for game ID:", gameId);
   const { data } = await api.get(`/games/${gameId}/players`);
   console.log(data);
-  return data;
+  return data.map((player: any) => player.profile?.displayName || player.username);
 };
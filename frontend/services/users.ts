@@ -1,11 +1,6 @@
 import AsyncStorage from "@react-native-async-storage/async-storage";
 import api from "./api";
 
-export const fetchAllUsers = async (): Promise<string[]> => {
-  const { data } = await api.get("/users");
-  return data.map((user: any) => user.username);
-};
-
 export const signupUser = async (
   username: string,
   password: string
@@ -23,6 +18,7 @@ export const loginUser = async (
 
     if (data?.token) {
       await AsyncStorage.setItem("jwt", data.token);
+      await AsyncStorage.setItem("refreshToken", data.refreshToken);
       return { success: true, user: { id: data.id, username: data.username } };
     }
 
@@ -34,3 +30,55 @@ export const loginUser = async (
     };
   }
 };
+
+export const joinAsGuest = async (
+  invite: string,
+  name: string
+): Promise<{ success: boolean; user?: any; gameId?: string; error?: string }> => {
+  try {
+    const { data } = await api.post("/guests", { invite, name });
+    await AsyncStorage.setItem("jwt", data.token);
+    await AsyncStorage.setItem("refreshToken", data.refreshToken);
+    return {
+      success: true,
+      user: { id: data.id, username: data.username, guest: true },
+      gameId: data.gameId,
+    };
+  } catch (err: any) {
+    return {
+      success: false,
+      error: err.response?.data?.error || "Could not join",
+    };
+  }
+};
+
+export const upgradeGuest = async (
+  username: string,
+  password: string,
+  email?: string
+): Promise<any> => {
+  const { data } = await api.post("/users/me/upgrade", { username, password, email });
+  return data;
+};
+
+export const logoutUser = async (everywhere = false): Promise<void> => {
+  const refreshToken = await AsyncStorage.getItem("refreshToken");
+  try {
+    if (everywhere) {
+      await api.post("/logout/all");
+    } else if (refreshToken) {
+      await api.post("/logout", { refreshToken });
+    }
+  } finally {
+    await AsyncStorage.multiRemove(["jwt", "refreshToken"]);
+  }
+};
+
+export const fetchSessions = async (): Promise<any[]> => {
+  const { data } = await api.get("/users/me/sessions");
+  return data;
+};
+
+export const revokeSession = async (sessionId: string): Promise<void> => {
+  await api.delete(`/users/me/sessions/${sessionId}`);
+};